@@ -0,0 +1,36 @@
+// mapgen — генерирует карту по шаблону и seed и печатает её в формате
+// content/maps, чтобы её можно было доработать вручную.
+//
+//	go run ./cmd/mapgen -template grassland -seed 42 -zones 4 > server/game/content/maps/gen.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"mpg/server/game"
+)
+
+func main() {
+	template := flag.String("template", "grassland", fmt.Sprintf("zone template %v", game.GenTemplateNames()))
+	seed := flag.Int64("seed", 1, "generator seed")
+	zones := flag.Int("zones", 3, "number of zones")
+	name := flag.String("name", "", "map name (default gen_<template>_<seed>)")
+	flag.Parse()
+
+	m, err := game.GenerateMap(*template, *seed, *zones)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *name != "" {
+		m.Name = *name
+	}
+
+	data, err := game.ExportMap(m)
+	if err != nil {
+		log.Fatal(err)
+	}
+	os.Stdout.Write(data)
+}
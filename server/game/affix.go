@@ -0,0 +1,232 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Affix — случайный модификатор элитного моба
+type Affix string
+
+const (
+	AffixFast         Affix = "fast"         // быстрее двигается
+	AffixArmored      Affix = "armored"      // получает меньше урона
+	AffixRegenerating Affix = "regenerating" // восстанавливает здоровье вне боя
+	AffixExplosive    Affix = "explosive"    // взрывается при смерти
+	AffixSplitting    Affix = "splitting"    // распадается на маленькие копии
+)
+
+// AffixConfig — влияние аффикса на моба
+type AffixConfig struct {
+	HealthMultiplier      float64 // множитель здоровья при спавне (0 — без изменений)
+	SpeedMultiplier       float64 // множитель скорости движения (0 — без изменений)
+	DamageTakenMultiplier float64 // множитель входящего урона (0 — без изменений)
+	RegenPerSecond        float64 // доля MaxHealth в секунду
+	RegenDelay            float64 // секунд без урона до начала регенерации
+	ExplodeRadius         float64
+	ExplodeDamage         float64 // доля урона моба
+	SplitCount            int
+	SplitScale            float64 // доля MaxHealth и радиуса у копий
+	LootBonusRolls        int     // дополнительные броски таблицы дропа
+}
+
+// AffixConfigs — параметры аффиксов
+var AffixConfigs = map[Affix]AffixConfig{
+	AffixFast: {
+		HealthMultiplier: 0.85, SpeedMultiplier: 1.35, LootBonusRolls: 1,
+	},
+	AffixArmored: {
+		HealthMultiplier: 1.2, DamageTakenMultiplier: 0.6, LootBonusRolls: 1,
+	},
+	AffixRegenerating: {
+		RegenPerSecond: 0.05, RegenDelay: 3, LootBonusRolls: 1,
+	},
+	AffixExplosive: {
+		ExplodeRadius: 120, ExplodeDamage: 2, LootBonusRolls: 1,
+	},
+	AffixSplitting: {
+		SplitCount: 2, SplitScale: 0.4, LootBonusRolls: 2,
+	},
+}
+
+// AffixZoneSettings — шанс стать элитой и максимум аффиксов в зоне.
+// Каждый следующий аффикс выпадает с тем же шансом, что и первый.
+type AffixZoneSettings struct {
+	Chance     float64 `json:"chance"`
+	MaxAffixes int     `json:"max_affixes"`
+}
+
+// ZoneAffixSettings — настройки аффиксов по зонам, если карта их не задаёт
+var ZoneAffixSettings = map[string]AffixZoneSettings{
+	"uncommon":  {Chance: 0.05, MaxAffixes: 1},
+	"rare":      {Chance: 0.1, MaxAffixes: 1},
+	"epic":      {Chance: 0.2, MaxAffixes: 2},
+	"legendary": {Chance: 0.3, MaxAffixes: 3},
+}
+
+// affixOrder — порядок аффиксов для детерминированного выбора
+var affixOrder = []Affix{AffixFast, AffixArmored, AffixRegenerating, AffixExplosive, AffixSplitting}
+
+// rollAffixes — случайные аффиксы для моба в зоне
+func rollAffixes(zone string) []Affix {
+	return rollAffixesWith(ZoneAffixSettings[zone])
+}
+
+// rollAffixesWith — случайные аффиксы по настройкам зоны
+func rollAffixesWith(settings AffixZoneSettings) []Affix {
+	if settings.MaxAffixes <= 0 {
+		return nil
+	}
+
+	pool := append([]Affix(nil), affixOrder...)
+	var affixes []Affix
+	for len(affixes) < settings.MaxAffixes && len(pool) > 0 && rand.Float64() < settings.Chance {
+		i := rand.Intn(len(pool))
+		affixes = append(affixes, pool[i])
+		pool = append(pool[:i], pool[i+1:]...)
+	}
+	return affixes
+}
+
+// applyAffixes — применяет стат-модификаторы аффиксов к новому мобу
+func (m *Mob) applyAffixes(affixes []Affix) {
+	m.Affixes = affixes
+	for _, affix := range affixes {
+		config := AffixConfigs[affix]
+		if config.HealthMultiplier > 0 {
+			m.MaxHealth = int(float64(m.MaxHealth) * config.HealthMultiplier)
+			m.Health = m.MaxHealth
+		}
+	}
+}
+
+// IsElite — есть ли у моба аффиксы
+func (m *Mob) IsElite() bool {
+	return len(m.Affixes) > 0
+}
+
+// AffixSpeedMultiplier — множитель скорости движения от аффиксов
+func (m *Mob) AffixSpeedMultiplier() float64 {
+	multiplier := 1.0
+	for _, affix := range m.Affixes {
+		if config := AffixConfigs[affix]; config.SpeedMultiplier > 0 {
+			multiplier *= config.SpeedMultiplier
+		}
+	}
+	return multiplier
+}
+
+// AffixDamageTakenMultiplier — множитель входящего урона от аффиксов
+func (m *Mob) AffixDamageTakenMultiplier() float64 {
+	multiplier := 1.0
+	for _, affix := range m.Affixes {
+		if config := AffixConfigs[affix]; config.DamageTakenMultiplier > 0 {
+			multiplier *= config.DamageTakenMultiplier
+		}
+	}
+	return multiplier
+}
+
+// affixLootBonusRolls — сколько дополнительных бросков дропа дают аффиксы
+func (m *Mob) affixLootBonusRolls() int {
+	rolls := 0
+	for _, affix := range m.Affixes {
+		rolls += AffixConfigs[affix].LootBonusRolls
+	}
+	return rolls
+}
+
+// rollAffixLootLocked — дополнительный дроп за аффиксы
+func (g *Game) rollAffixLootLocked(mob *Mob) []LootDrop {
+	rolls := mob.affixLootBonusRolls()
+	if rolls == 0 {
+		return nil
+	}
+	table, ok := lootTableFor(mob.Type, mob.Rarity)
+	if !ok {
+		return nil
+	}
+	table.Rolls = rolls
+	return table.Roll(g.lootRng, mob.Rarity)
+}
+
+// updateAffixRegenLocked — регенерация, если моб давно не получал урон
+func (g *Game) updateAffixRegenLocked(mob *Mob, now time.Time, deltaTime float64) {
+	for _, affix := range mob.Affixes {
+		config := AffixConfigs[affix]
+		if config.RegenPerSecond <= 0 || mob.Health >= mob.MaxHealth {
+			continue
+		}
+		if mob.IsRetaliating(now, seconds(config.RegenDelay)) {
+			continue
+		}
+		mob.regenCarry += float64(mob.MaxHealth) * config.RegenPerSecond * deltaTime
+		heal := int(mob.regenCarry)
+		mob.regenCarry -= float64(heal)
+		mob.Health += heal
+		if mob.Health > mob.MaxHealth {
+			mob.Health = mob.MaxHealth
+		}
+	}
+}
+
+// triggerAffixDeathLocked — срабатывание аффиксов при смерти моба
+func (g *Game) triggerAffixDeathLocked(mob *Mob, now time.Time) {
+	for _, affix := range mob.Affixes {
+		config := AffixConfigs[affix]
+		if config.ExplodeRadius > 0 {
+			g.explodeMobLocked(mob, config, now)
+		}
+		if config.SplitCount > 0 {
+			g.splitMobLocked(mob, config, now)
+		}
+	}
+}
+
+// explodeMobLocked — урон всем игрокам рядом с местом смерти
+func (g *Game) explodeMobLocked(mob *Mob, config AffixConfig, now time.Time) {
+	damage := scaleDamage(mob.Damage, config.ExplodeDamage)
+	for _, player := range g.players {
+		if player.CurrentZone != mob.Zone {
+			continue
+		}
+		if player.DistanceTo(mob.X, mob.Y) <= config.ExplodeRadius+player.Radius {
+			g.mobHitPlayerLocked(mob, player, damage, now)
+		}
+	}
+
+	g.broadcastToZoneLocked(mob.Zone, map[string]interface{}{
+		"type": "mob_exploded",
+		"data": map[string]interface{}{
+			"mob_id": mob.ID,
+			"x":      mob.X,
+			"y":      mob.Y,
+			"radius": config.ExplodeRadius,
+		},
+	})
+}
+
+// splitMobLocked — создаёт уменьшенные копии без аффиксов
+func (g *Game) splitMobLocked(mob *Mob, config AffixConfig, now time.Time) {
+	for i := 0; i < config.SplitCount; i++ {
+		angle := 2 * math.Pi * float64(i) / float64(config.SplitCount)
+		x := mob.X + math.Cos(angle)*mob.Radius
+		y := mob.Y + math.Sin(angle)*mob.Radius
+		x, y = g.constrainMobToZone(mob, x, y)
+
+		g.entitySeq++
+		childID := fmt.Sprintf("split_%s_%d", mob.ID, g.entitySeq)
+		child := newMobWithRarity(childID, mob.Type, mob.Rarity, x, y, mob.Zone)
+		child.MaxHealth = int(math.Max(1, float64(mob.MaxHealth)*config.SplitScale))
+		child.Health = child.MaxHealth
+		child.Radius = math.Max(2, mob.Radius*math.Sqrt(config.SplitScale))
+		child.TargetPlayer = mob.TargetPlayer
+		child.LastHitTime = now // копии сразу в бою
+		for playerID, threat := range mob.Threat {
+			g.addThreatLocked(child, playerID, threat*config.SplitScale)
+		}
+		g.mobs[childID] = child
+	}
+}
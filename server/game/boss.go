@@ -0,0 +1,459 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// BossAdd — мобы, которых босс призывает при входе в фазу
+type BossAdd struct {
+	Type  MobType
+	Count int
+}
+
+// BossSpecial — телеграфируемая атака по области: сначала клиенты получают
+// предупреждение, через Delay секунд урон получают все игроки в радиусе
+type BossSpecial struct {
+	Name     string
+	Radius   float64
+	Damage   int
+	Delay    float64 // секунды от предупреждения до удара
+	Interval float64 // секунды между атаками
+}
+
+// BossPhase — фаза боя, начинается, когда здоровье падает ниже HealthBelow
+type BossPhase struct {
+	Name             string
+	HealthBelow      float64 // доля MaxHealth (0..1), у первой фазы — 1
+	DamageMultiplier float64
+	Adds             []BossAdd
+	Special          *BossSpecial
+}
+
+// BossEncounter — описание энкаунтера
+type BossEncounter struct {
+	ID           string
+	MobType      MobType
+	Rarity       Rarity
+	Zone         string
+	X, Y         float64
+	RespawnDelay time.Duration
+	EnrageAfter  time.Duration // с момента первого удара
+	EnrageDamage float64       // множитель урона в ярости
+	Phases       []BossPhase
+}
+
+// Параметры распределения лута босса
+const (
+	BossMinContribution = 0.05 // минимальная доля урона для получения награды
+	BossBonusRolls      = 3    // дополнительные броски за 100% вклада
+	BossAddSpread       = 120.0
+)
+
+// BossEncounters — энкаунтеры, которые планирует bossLoop
+var BossEncounters = []BossEncounter{
+	{
+		ID:           "ogre_king",
+		MobType:      MobTypeOgreKing,
+		Rarity:       RarityLegendary,
+		Zone:         "legendary",
+		X:            31000,
+		Y:            1500,
+		RespawnDelay: 10 * time.Minute,
+		EnrageAfter:  4 * time.Minute,
+		EnrageDamage: 2.5,
+		Phases: []BossPhase{
+			{
+				Name: "awakening", HealthBelow: 1, DamageMultiplier: 1,
+				Special: &BossSpecial{Name: "slam", Radius: 180, Damage: 60, Delay: 1.5, Interval: 12},
+			},
+			{
+				Name: "warband", HealthBelow: 0.66, DamageMultiplier: 1.25,
+				Adds:    []BossAdd{{MobTypeOrc, 3}},
+				Special: &BossSpecial{Name: "slam", Radius: 220, Damage: 80, Delay: 1.25, Interval: 9},
+			},
+			{
+				Name: "frenzy", HealthBelow: 0.33, DamageMultiplier: 1.6,
+				Adds:    []BossAdd{{MobTypeWolf, 4}},
+				Special: &BossSpecial{Name: "quake", Radius: 300, Damage: 100, Delay: 1, Interval: 6},
+			},
+		},
+	},
+}
+
+// bossTelegraph — объявленная, но ещё не нанесённая атака
+type bossTelegraph struct {
+	Special  BossSpecial
+	X, Y     float64
+	StrikeAt time.Time
+}
+
+// bossState — состояние энкаунтера во время игры
+type bossState struct {
+	Encounter    BossEncounter
+	MobID        string
+	NextSpawn    time.Time
+	EngagedAt    time.Time
+	Phase        int
+	Enraged      bool
+	BaseDamage   int
+	LastSpecial  time.Time
+	Pending      []bossTelegraph
+	Contribution map[string]int // playerID → нанесённый урон
+}
+
+// bossEncounterExists — есть ли энкаунтер с таким ID
+func bossEncounterExists(id string) bool {
+	for _, encounter := range BossEncounters {
+		if encounter.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// bossLoop — спавн, фазы и атаки боссов 10 раз в секунду
+func (g *Game) bossLoop() {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for g.tick(ticker) {
+		g.updateBosses()
+	}
+}
+
+func (g *Game) updateBosses() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+
+	for i := range BossEncounters {
+		encounter := BossEncounters[i]
+		if g.zones[encounter.Zone] == nil {
+			continue // на этой карте нет зоны босса
+		}
+		state, ok := g.bosses[encounter.ID]
+		if !ok {
+			state = &bossState{Encounter: encounter, NextSpawn: now}
+			g.bosses[encounter.ID] = state
+		}
+
+		if state.MobID == "" {
+			if !now.Before(state.NextSpawn) {
+				g.spawnBossLocked(state, now)
+			}
+			continue
+		}
+
+		mob := g.mobs[state.MobID]
+		if mob == nil || !mob.IsAlive() {
+			// Босс пропал мимо handleBossKilledLocked (закрытие подземелья,
+			// перезагрузка контента) — планируем следующее появление
+			g.bossLostLocked(state, now)
+			continue
+		}
+
+		// Босса утянули за поводок — бой начинается заново
+		if mob.State == MobStateReturning {
+			if !state.EngagedAt.IsZero() {
+				g.resetBossLocked(state, mob)
+			}
+			continue
+		}
+
+		g.updateBossPhaseLocked(state, mob, now)
+		g.updateBossEnrageLocked(state, mob, now)
+		g.updateBossSpecialLocked(state, mob, now)
+	}
+}
+
+// bossLostLocked — босс исчез без убийства: сбрасываем бой и ставим респаун
+func (g *Game) bossLostLocked(state *bossState, now time.Time) {
+	fmt.Printf("👑 Boss %s is gone, next spawn in %s\n", state.Encounter.ID, state.Encounter.RespawnDelay)
+	state.MobID = ""
+	state.NextSpawn = now.Add(state.Encounter.RespawnDelay)
+	resetBossFight(state)
+}
+
+// spawnBossLocked — создаёт моба-босса и объявляет о нём зоне
+func (g *Game) spawnBossLocked(state *bossState, now time.Time) {
+	encounter := state.Encounter
+	mobID := fmt.Sprintf("boss_%s_%d", encounter.ID, now.UnixNano())
+	mob := newMobWithRarity(mobID, encounter.MobType, encounter.Rarity, encounter.X, encounter.Y, encounter.Zone)
+	mob.BossID = encounter.ID
+	g.mobs[mobID] = mob
+
+	state.MobID = mobID
+	state.BaseDamage = mob.Damage
+	g.resetBossLocked(state, mob)
+
+	fmt.Printf("👑 Boss %s spawned in %s\n", encounter.ID, encounter.Zone)
+	g.broadcastToZoneLocked(encounter.Zone, map[string]interface{}{
+		"type": "boss_spawned",
+		"data": map[string]interface{}{
+			"boss_id":    encounter.ID,
+			"mob_id":     mob.ID,
+			"x":          mob.X,
+			"y":          mob.Y,
+			"max_health": mob.MaxHealth,
+		},
+	})
+}
+
+// resetBossLocked — возвращает бой к первой фазе
+func (g *Game) resetBossLocked(state *bossState, mob *Mob) {
+	resetBossFight(state)
+	mob.Damage = state.BaseDamage
+}
+
+// resetBossFight — сбрасывает фазу, ярость и вклад участников
+func resetBossFight(state *bossState) {
+	state.EngagedAt = time.Time{}
+	state.Phase = 0
+	state.Enraged = false
+	state.Pending = nil
+	state.LastSpecial = time.Time{}
+	state.Contribution = make(map[string]int)
+}
+
+// recordBossContributionLocked — учитывает урон игрока по боссу
+func (g *Game) recordBossContributionLocked(mob *Mob, playerID string, damage int) {
+	if mob.BossID == "" {
+		return
+	}
+	state := g.bosses[mob.BossID]
+	if state == nil || state.MobID != mob.ID {
+		return
+	}
+	if state.EngagedAt.IsZero() {
+		state.EngagedAt = time.Now()
+	}
+	state.Contribution[playerID] += damage
+}
+
+// updateBossPhaseLocked — переключает фазы по порогам здоровья
+func (g *Game) updateBossPhaseLocked(state *bossState, mob *Mob, now time.Time) {
+	phases := state.Encounter.Phases
+	healthShare := float64(mob.Health) / float64(mob.MaxHealth)
+
+	for state.Phase+1 < len(phases) && healthShare < phases[state.Phase+1].HealthBelow {
+		state.Phase++
+		phase := phases[state.Phase]
+		g.applyBossDamageLocked(state, mob)
+		state.LastSpecial = now // даём игрокам передышку перед новой атакой
+
+		for _, add := range phase.Adds {
+			for i := 0; i < add.Count; i++ {
+				angle := 2 * math.Pi * float64(i) / float64(add.Count)
+				x := mob.X + math.Cos(angle)*BossAddSpread
+				y := mob.Y + math.Sin(angle)*BossAddSpread
+				g.entitySeq++
+				addID := fmt.Sprintf("add_%s_%d", mob.BossID, g.entitySeq)
+				addMob := NewMob(addID, add.Type, x, y, mob.Zone)
+				addMob.TargetPlayer = mob.TargetPlayer
+				g.mobs[addID] = addMob
+			}
+		}
+
+		g.broadcastToZoneLocked(mob.Zone, map[string]interface{}{
+			"type": "boss_phase",
+			"data": map[string]interface{}{
+				"boss_id": state.Encounter.ID,
+				"mob_id":  mob.ID,
+				"phase":   state.Phase,
+				"name":    phase.Name,
+			},
+		})
+	}
+}
+
+// updateBossEnrageLocked — включает ярость, если бой затянулся
+func (g *Game) updateBossEnrageLocked(state *bossState, mob *Mob, now time.Time) {
+	encounter := state.Encounter
+	if state.Enraged || state.EngagedAt.IsZero() || encounter.EnrageAfter <= 0 {
+		return
+	}
+	if now.Sub(state.EngagedAt) < encounter.EnrageAfter {
+		return
+	}
+
+	state.Enraged = true
+	g.applyBossDamageLocked(state, mob)
+	g.broadcastToZoneLocked(mob.Zone, map[string]interface{}{
+		"type": "boss_enraged",
+		"data": map[string]interface{}{
+			"boss_id": encounter.ID,
+			"mob_id":  mob.ID,
+		},
+	})
+}
+
+// applyBossDamageLocked — пересчитывает урон босса по фазе и ярости
+func (g *Game) applyBossDamageLocked(state *bossState, mob *Mob) {
+	multiplier := state.Encounter.Phases[state.Phase].DamageMultiplier
+	if state.Enraged {
+		multiplier *= state.Encounter.EnrageDamage
+	}
+	mob.Damage = scaleDamage(state.BaseDamage, multiplier)
+}
+
+// updateBossSpecialLocked — объявляет и наносит телеграфируемые атаки
+func (g *Game) updateBossSpecialLocked(state *bossState, mob *Mob, now time.Time) {
+	// Сначала разрешаем атаки, время которых пришло
+	pending := state.Pending[:0]
+	for _, telegraph := range state.Pending {
+		if now.Before(telegraph.StrikeAt) {
+			pending = append(pending, telegraph)
+			continue
+		}
+		g.resolveBossSpecialLocked(mob, telegraph)
+	}
+	state.Pending = pending
+
+	special := state.Encounter.Phases[state.Phase].Special
+	if special == nil || state.EngagedAt.IsZero() || mob.TargetPlayer == "" || !mob.CanAttack() {
+		return
+	}
+	interval := seconds(special.Interval)
+	if state.Enraged {
+		interval /= 2
+	}
+	if now.Sub(state.LastSpecial) < interval {
+		return
+	}
+
+	target := g.players[mob.TargetPlayer]
+	if target == nil || !target.IsAlive() || target.CurrentZone != mob.Zone {
+		return
+	}
+
+	state.LastSpecial = now
+	telegraph := bossTelegraph{
+		Special:  *special,
+		X:        target.X,
+		Y:        target.Y,
+		StrikeAt: now.Add(seconds(special.Delay)),
+	}
+	state.Pending = append(state.Pending, telegraph)
+
+	g.broadcastToZoneLocked(mob.Zone, map[string]interface{}{
+		"type": "boss_telegraph",
+		"data": map[string]interface{}{
+			"boss_id":  state.Encounter.ID,
+			"mob_id":   mob.ID,
+			"attack":   special.Name,
+			"x":        telegraph.X,
+			"y":        telegraph.Y,
+			"radius":   special.Radius,
+			"delay_ms": seconds(special.Delay).Milliseconds(),
+		},
+	})
+}
+
+// resolveBossSpecialLocked — урон всем игрокам в области телеграфа
+func (g *Game) resolveBossSpecialLocked(mob *Mob, telegraph bossTelegraph) {
+	damage := telegraph.Special.Damage
+	if mob.Damage > 0 && mob.BossID != "" {
+		if state := g.bosses[mob.BossID]; state != nil && state.BaseDamage > 0 {
+			damage = scaleDamage(damage, float64(mob.Damage)/float64(state.BaseDamage))
+		}
+	}
+
+	for _, player := range g.players {
+		if !player.IsAlive() || player.CurrentZone != mob.Zone || player.InSafeZone {
+			continue
+		}
+		dx := player.X - telegraph.X
+		dy := player.Y - telegraph.Y
+		if math.Sqrt(dx*dx+dy*dy) > telegraph.Special.Radius+player.Radius {
+			continue
+		}
+
+		hit := scaleDamage(damage, player.StanceIncomingMultiplier()*player.Effects.IncomingDamageMultiplier())
+		hit = g.absorbPlayerDamage(player, hit)
+		player.Health -= hit
+		if player.Health < 0 {
+			player.Health = 0
+		}
+		g.sendDamageNotification(player, hit)
+		if !player.IsAlive() {
+			g.handlePlayerDeath(player)
+		}
+	}
+}
+
+// handleBossKilledLocked — делит лут по вкладу, объявляет победу и
+// планирует следующее появление босса
+func (g *Game) handleBossKilledLocked(mob *Mob) {
+	state := g.bosses[mob.BossID]
+	if state == nil || state.MobID != mob.ID {
+		return
+	}
+	encounter := state.Encounter
+
+	total := 0
+	for _, damage := range state.Contribution {
+		total += damage
+	}
+
+	// Сортируем участников по вкладу для стабильного объявления
+	participants := make([]string, 0, len(state.Contribution))
+	for playerID := range state.Contribution {
+		participants = append(participants, playerID)
+	}
+	sort.Slice(participants, func(i, j int) bool {
+		return state.Contribution[participants[i]] > state.Contribution[participants[j]]
+	})
+
+	table, hasTable := lootTableFor(mob.Type, mob.Rarity)
+	rewards := make([]map[string]interface{}, 0, len(participants))
+	for _, playerID := range participants {
+		share := 0.0
+		if total > 0 {
+			share = float64(state.Contribution[playerID]) / float64(total)
+		}
+		player := g.players[playerID]
+		if share < BossMinContribution || player == nil || player.CurrentZone != mob.Zone {
+			continue
+		}
+
+		// Больше вклад — больше бросков таблицы
+		var drops []LootDrop
+		if hasTable {
+			rolls := 1 + int(share*BossBonusRolls)
+			for i := 0; i < rolls; i++ {
+				drops = append(drops, table.Roll(g.lootRng, mob.Rarity)...)
+			}
+		}
+		g.dropLootLocked(playerID, mob, drops)
+		player.DefeatedBosses[encounter.ID] = true
+		g.grantXPLocked(player, mobXP(mob))
+		g.saveProgressLocked(player)
+
+		rewards = append(rewards, map[string]interface{}{
+			"player_id": playerID,
+			"username":  player.Username,
+			"damage":    state.Contribution[playerID],
+			"share":     share,
+			"drops":     len(drops),
+		})
+	}
+
+	state.MobID = ""
+	state.NextSpawn = time.Now().Add(encounter.RespawnDelay)
+	g.resetBossLocked(state, mob)
+
+	fmt.Printf("👑 Boss %s defeated, next spawn at %s\n", encounter.ID, state.NextSpawn.Format(time.TimeOnly))
+	g.broadcastToZoneLocked(mob.Zone, map[string]interface{}{
+		"type": "boss_defeated",
+		"data": map[string]interface{}{
+			"boss_id":       encounter.ID,
+			"mob_id":        mob.ID,
+			"participants":  rewards,
+			"next_spawn_ms": state.NextSpawn.UnixMilli(),
+		},
+	})
+}
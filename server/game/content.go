@@ -0,0 +1,64 @@
+package game
+
+import (
+	"fmt"
+	"io/fs"
+	"sync"
+)
+
+// contentMu охраняет глобальный контент (Maps, mobAIMachines): его читают
+// циклы всех комнат, каждая под своим g.mu, а перезагрузка подменяет целиком
+var contentMu sync.RWMutex
+
+// Content — разобранный и проверенный контент: карты и автоматы ИИ мобов
+type Content struct {
+	Maps  map[string]*MapDef
+	MobAI map[string]AIMachineDef
+}
+
+// LoadContent читает контент из fsys (nil — встроенный) и проверяет его.
+// Глобальное состояние не меняется — для этого есть InstallContent.
+func LoadContent(fsys fs.FS) (*Content, error) {
+	if fsys == nil {
+		fsys = contentFiles
+	}
+
+	aiData, err := fs.ReadFile(fsys, "content/mob_ai.json")
+	if err != nil {
+		return nil, fmt.Errorf("content: %w", err)
+	}
+	machines, err := parseMobAI(aiData)
+	if err != nil {
+		return nil, fmt.Errorf("content: %w", err)
+	}
+	maps, err := loadMapsFS(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("content: %w", err)
+	}
+	return &Content{Maps: maps, MobAI: machines}, nil
+}
+
+// InstallContent подменяет глобальные карты и автоматы ИИ. Игры после этого
+// нужно пересобрать через ApplyContent.
+func InstallContent(c *Content) {
+	contentMu.Lock()
+	defer contentMu.Unlock()
+	Maps = c.Maps
+	mobAIMachines = c.MobAI
+}
+
+// lookupMap — карта из content/maps по имени
+func lookupMap(name string) (*MapDef, bool) {
+	contentMu.RLock()
+	defer contentMu.RUnlock()
+	m, ok := Maps[name]
+	return m, ok
+}
+
+// lookupMobAI — автомат ИИ по имени
+func lookupMobAI(name string) (AIMachineDef, bool) {
+	contentMu.RLock()
+	defer contentMu.RUnlock()
+	machine, ok := mobAIMachines[name]
+	return machine, ok
+}
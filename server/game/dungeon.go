@@ -0,0 +1,541 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// DungeonObjectiveKind — условие прохождения подземелья
+type DungeonObjectiveKind string
+
+const (
+	DungeonKillAll   DungeonObjectiveKind = "kill_all"   // перебить всех мобов инстанса
+	DungeonKillCount DungeonObjectiveKind = "kill_count" // убить Count мобов
+)
+
+// Значения по умолчанию для шаблонов подземелий
+const (
+	DefaultDungeonMobs         = 12
+	DefaultDungeonMaxPlayers   = 4
+	DefaultDungeonTimeLimit    = 15 * 60 // секунд
+	DefaultDungeonEmptyTimeout = 30      // секунд без игроков до закрытия
+	DungeonSpawnClearance      = 300.0   // мобы не появляются у входа
+)
+
+// DungeonObjective — цель подземелья
+type DungeonObjective struct {
+	Kind  DungeonObjectiveKind `json:"kind"`
+	Count int                  `json:"count,omitempty"` // для kill_count
+}
+
+// DungeonTemplate — шаблон подземелья из файла карты. Каждая группа,
+// вошедшая через портал с Dungeon, получает свою копию зоны Zone.
+type DungeonTemplate struct {
+	ID           string           `json:"id"`
+	Name         string           `json:"name"`
+	Zone         *Zone            `json:"zone"` // имя не важно — у копии оно своё
+	Entry        Point            `json:"entry"`
+	Exit         Point            `json:"exit"` // портал обратно в мир
+	Objective    DungeonObjective `json:"objective"`
+	MaxPlayers   int              `json:"max_players,omitempty"`
+	TimeLimit    float64          `json:"time_limit,omitempty"`    // секунд
+	EmptyTimeout float64          `json:"empty_timeout,omitempty"` // секунд
+	RewardXP     int              `json:"reward_xp,omitempty"`
+	Rewards      []LootDrop       `json:"rewards,omitempty"` // каждому участнику при прохождении
+}
+
+// validate проверяет шаблон подземелья
+func (d *DungeonTemplate) validate() []error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("dungeon %q: "+format, append([]interface{}{d.ID}, args...)...))
+	}
+
+	if d.ID == "" {
+		fail("missing id")
+	}
+	z := d.Zone
+	if z == nil {
+		fail("missing zone")
+		return errs
+	}
+	if z.MinX >= z.MaxX || z.MinY >= z.MaxY {
+		fail("empty bounds")
+	}
+	if !z.Contains(d.Entry.X, d.Entry.Y) {
+		fail("entry is outside the zone")
+	}
+	if !z.Contains(d.Exit.X, d.Exit.Y) {
+		fail("exit is outside the zone")
+	}
+	for _, mobType := range z.MobTypes {
+		if err := validateSpawnableMob(mobType); err != nil {
+			fail("%v", err)
+		}
+	}
+	for rarity := range z.RarityDistribution {
+		if _, ok := RarityMultipliers[rarity]; !ok {
+			fail("unknown rarity %q", rarity)
+		}
+	}
+	for _, o := range z.Obstacles {
+		if err := o.validate(); err != nil {
+			fail("%v", err)
+		}
+	}
+	switch d.Objective.Kind {
+	case DungeonKillAll:
+	case DungeonKillCount:
+		if d.Objective.Count <= 0 {
+			fail("kill_count objective needs positive count")
+		}
+		if mobs := d.mobCount(); d.Objective.Count > mobs {
+			fail("kill_count objective needs %d kills, but only %d mobs spawn", d.Objective.Count, mobs)
+		}
+	default:
+		fail("unknown objective %q", d.Objective.Kind)
+	}
+	for _, reward := range d.Rewards {
+		if _, ok := PetalConfigs[reward.Type]; !ok {
+			fail("unknown reward petal %q", reward.Type)
+		}
+	}
+	return errs
+}
+
+// dungeonInstance — работающая копия подземелья
+type dungeonInstance struct {
+	ID           string // он же имя зоны инстанса
+	Template     *DungeonTemplate
+	Owner        string  // группа или игрок, для которого создан инстанс
+	ReturnPortal *Portal // портал в мире, через который вошли
+	ExitPortalID string
+	Created      time.Time
+	Deadline     time.Time
+	LastOccupied time.Time
+	Kills        int
+	Completed    bool
+	CompletedAt  time.Time
+}
+
+// DungeonInstanceInfo — инстанс в метриках
+type DungeonInstanceInfo struct {
+	ID         string  `json:"id"`
+	Dungeon    string  `json:"dungeon"`
+	Owner      string  `json:"owner"`
+	Players    int     `json:"players"`
+	Mobs       int     `json:"mobs"`
+	AgeSeconds float64 `json:"age_seconds"`
+	Completed  bool    `json:"completed"`
+}
+
+// DungeonMetrics — сколько инстансов живёт сейчас и сколько жили раньше
+type DungeonMetrics struct {
+	Active             int                   `json:"active"`
+	Created            int                   `json:"created"`
+	Completed          int                   `json:"completed"`
+	Closed             map[string]int        `json:"closed"` // по причине закрытия
+	AvgLifetimeSeconds float64               `json:"avg_lifetime_seconds"`
+	MaxLifetimeSeconds float64               `json:"max_lifetime_seconds"`
+	Instances          []DungeonInstanceInfo `json:"instances"`
+}
+
+// dungeonStats — накопленная статистика закрытых инстансов
+type dungeonStats struct {
+	Created       int
+	Completed     int
+	Closed        map[string]int
+	TotalLifetime time.Duration
+	MaxLifetime   time.Duration
+}
+
+// dungeonOwner — ключ, по которому участники попадают в один инстанс
+func dungeonOwner(player *Player) string {
+	if player.PartyID != "" {
+		return "party:" + player.PartyID
+	}
+	return "solo:" + player.ID
+}
+
+// dungeonLoop — цели и закрытие инстансов 10 раз в секунду
+func (g *Game) dungeonLoop() {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for g.tick(ticker) {
+		g.updateDungeons()
+	}
+}
+
+func (g *Game) updateDungeons() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	for _, inst := range g.dungeons {
+		if g.dungeonPlayersLocked(inst) > 0 {
+			inst.LastOccupied = now
+		}
+
+		if !inst.Completed && g.dungeonObjectiveDoneLocked(inst) {
+			g.completeDungeonLocked(inst, now)
+		}
+
+		switch {
+		case now.Sub(inst.LastOccupied) > seconds(inst.Template.EmptyTimeout):
+			g.closeDungeonLocked(inst, "empty", now)
+		case !inst.Completed && now.After(inst.Deadline):
+			g.closeDungeonLocked(inst, "timeout", now)
+		}
+	}
+}
+
+// enterDungeonLocked — вход через портал подземелья: в инстанс своей
+// группы или в новый
+func (g *Game) enterDungeonLocked(player *Player, portal *Portal) {
+	template := g.mapDef.dungeon(portal.Dungeon)
+	if template == nil {
+		return
+	}
+	if ok, reason := portal.Requirements.CheckRequirements(player); !ok {
+		g.rejectPortalLocked(player, portal, template.ID, reason)
+		return
+	}
+
+	owner := dungeonOwner(player)
+	var inst *dungeonInstance
+	for _, candidate := range g.dungeons {
+		if candidate.Template == template && candidate.Owner == owner {
+			inst = candidate
+			break
+		}
+	}
+	if inst == nil {
+		inst = g.createDungeonLocked(template, owner, portal)
+		if inst == nil {
+			g.rejectPortalLocked(player, portal, template.ID, "Dungeon could not be populated")
+			return
+		}
+	} else if g.dungeonPlayersLocked(inst) >= template.MaxPlayers {
+		g.rejectPortalLocked(player, portal, inst.ID, "Dungeon instance is full")
+		return
+	}
+
+	player.X, player.Y = template.Entry.X, template.Entry.Y
+	player.CurrentZone = inst.ID
+	player.PortalCooldown = time.Now().Add(10 * time.Second)
+	inst.LastOccupied = time.Now()
+
+	if conn, ok := g.connections[player.ID]; ok {
+		conn.WriteJSON(map[string]interface{}{
+			"type": "dungeon_entered",
+			"data": map[string]interface{}{
+				"instance_id": inst.ID,
+				"dungeon":     template.ID,
+				"name":        template.Name,
+				"zone":        g.zones[inst.ID],
+				"objective":   template.Objective,
+				"kills":       inst.Kills,
+				"target":      g.dungeonTargetLocked(inst),
+				"time_left":   time.Until(inst.Deadline).Seconds(),
+			},
+		})
+	}
+	g.sendZoneObstaclesLocked(player)
+
+	fmt.Printf("🏰 %s entered dungeon %s\n", player.ID, inst.ID)
+}
+
+// createDungeonLocked — копия зоны шаблона со своими мобами и выходом.
+// Если мобов для цели не хватило, инстанс сразу закрывается и возвращается nil.
+func (g *Game) createDungeonLocked(template *DungeonTemplate, owner string, portal *Portal) *dungeonInstance {
+	now := time.Now()
+	g.dungeonSeq++
+	id := fmt.Sprintf("dungeon_%s_%d", template.ID, g.dungeonSeq)
+
+	zone := *template.Zone
+	zone.Name = id
+	zone.SpawnRegions = nil
+	zone.POIs = nil
+	g.zones[id] = &zone
+	if len(zone.Obstacles) > 0 {
+		g.navGrids[id] = buildNavGrid(&zone)
+	}
+
+	exit := &Portal{
+		ID:     id + "_exit",
+		Zone:   id,
+		X:      template.Exit.X,
+		Y:      template.Exit.Y,
+		Radius: DefaultPortalRadius,
+		To:     portal.ID,
+	}
+	g.portals[exit.ID] = exit
+
+	inst := &dungeonInstance{
+		ID:           id,
+		Template:     template,
+		Owner:        owner,
+		ReturnPortal: portal,
+		ExitPortalID: exit.ID,
+		Created:      now,
+		Deadline:     now.Add(seconds(template.TimeLimit)),
+		LastOccupied: now,
+	}
+	g.dungeons[id] = inst
+	g.dungeonStats.Created++
+
+	required := 1
+	if template.Objective.Kind == DungeonKillCount {
+		required = template.Objective.Count
+	}
+	if spawned := g.spawnDungeonMobsLocked(inst); spawned < required {
+		g.closeDungeonLocked(inst, "spawn_failed", now)
+		return nil
+	}
+	fmt.Printf("🏰 Dungeon %s created for %s\n", id, owner)
+	return inst
+}
+
+// mobCount — сколько мобов появляется в инстансе
+func (d *DungeonTemplate) mobCount() int {
+	if d.Zone.MaxMobs == 0 {
+		return DefaultDungeonMobs
+	}
+	return d.Zone.MaxMobs
+}
+
+// spawnDungeonMobsLocked — все мобы инстанса появляются сразу и не возрождаются.
+// Возвращает, сколько мобов удалось расставить.
+func (g *Game) spawnDungeonMobsLocked(inst *dungeonInstance) int {
+	zone := g.zones[inst.ID]
+	count := inst.Template.mobCount()
+	mobTypes := zone.spawnableMobTypes(nil)
+	entry := inst.Template.Entry
+
+	spawned := 0
+	for attempts := 0; spawned < count && attempts < count*10; attempts++ {
+		mobType := mobTypes[rand.Intn(len(mobTypes))]
+		x := zone.MinX + rand.Float64()*(zone.MaxX-zone.MinX)
+		y := zone.MinY + rand.Float64()*(zone.MaxY-zone.MinY)
+		dx, dy := x-entry.X, y-entry.Y
+		if dx*dx+dy*dy < DungeonSpawnClearance*DungeonSpawnClearance {
+			continue
+		}
+		if g.blockedLocked(inst.ID, x, y, MobConfigs[mobType].Radius*2) || g.inSafeRegionLocked(inst.ID, x, y) {
+			continue
+		}
+
+		mobID := fmt.Sprintf("mob_%s_%d_%d", inst.ID, time.Now().UnixNano(), spawned)
+		mob := newMobWithRarity(mobID, mobType, zone.rollRarity(), x, y, inst.ID)
+		mob.applyAffixes(rollAffixesWith(zone.affixSettings()))
+		g.mobs[mobID] = mob
+		spawned++
+	}
+	return spawned
+}
+
+// recordDungeonKillLocked — засчитывает убийство в цель инстанса
+func (g *Game) recordDungeonKillLocked(mob *Mob) {
+	inst := g.dungeons[mob.Zone]
+	if inst == nil || inst.Completed {
+		return
+	}
+	inst.Kills++
+	g.broadcastToZoneLocked(inst.ID, map[string]interface{}{
+		"type": "dungeon_progress",
+		"data": map[string]interface{}{
+			"instance_id": inst.ID,
+			"kills":       inst.Kills,
+			"target":      g.dungeonTargetLocked(inst),
+		},
+	})
+}
+
+// dungeonTargetLocked — сколько убийств нужно для прохождения
+func (g *Game) dungeonTargetLocked(inst *dungeonInstance) int {
+	if inst.Template.Objective.Kind == DungeonKillCount {
+		return inst.Template.Objective.Count
+	}
+	return inst.Kills + g.dungeonMobsLocked(inst)
+}
+
+func (g *Game) dungeonObjectiveDoneLocked(inst *dungeonInstance) bool {
+	switch inst.Template.Objective.Kind {
+	case DungeonKillCount:
+		return inst.Kills >= inst.Template.Objective.Count
+	default:
+		return g.dungeonMobsLocked(inst) == 0
+	}
+}
+
+func (g *Game) dungeonMobsLocked(inst *dungeonInstance) int {
+	count := 0
+	for _, mob := range g.mobs {
+		if mob.Zone == inst.ID && mob.IsAlive() {
+			count++
+		}
+	}
+	return count
+}
+
+func (g *Game) dungeonPlayersLocked(inst *dungeonInstance) int {
+	count := 0
+	for _, player := range g.players {
+		if player.CurrentZone == inst.ID {
+			count++
+		}
+	}
+	return count
+}
+
+// completeDungeonLocked — награда всем, кто внутри, и объявление
+func (g *Game) completeDungeonLocked(inst *dungeonInstance, now time.Time) {
+	inst.Completed = true
+	inst.CompletedAt = now
+	g.dungeonStats.Completed++
+
+	template := inst.Template
+	for _, player := range g.players {
+		if player.CurrentZone != inst.ID || !player.IsAlive() {
+			continue
+		}
+		for _, reward := range template.Rewards {
+			g.createPetalDrop(player.ID, reward.Type, reward.Rarity, player.X, player.Y)
+		}
+		g.grantXPLocked(player, template.RewardXP)
+	}
+
+	g.broadcastToZoneLocked(inst.ID, map[string]interface{}{
+		"type": "dungeon_completed",
+		"data": map[string]interface{}{
+			"instance_id": inst.ID,
+			"dungeon":     template.ID,
+			"kills":       inst.Kills,
+			"duration":    now.Sub(inst.Created).Seconds(),
+			"reward_xp":   template.RewardXP,
+			"rewards":     template.Rewards,
+		},
+	})
+	fmt.Printf("🏆 Dungeon %s completed in %s\n", inst.ID, now.Sub(inst.Created).Round(time.Second))
+}
+
+// closeDungeonLocked — выводит игроков и удаляет всё, что жило в инстансе
+func (g *Game) closeDungeonLocked(inst *dungeonInstance, reason string, now time.Time) {
+	back := inst.ReturnPortal
+	for _, player := range g.players {
+		if player.CurrentZone != inst.ID {
+			continue
+		}
+		player.X, player.Y = back.X, back.Y
+		player.CurrentZone = back.Zone
+		player.PortalCooldown = now.Add(10 * time.Second)
+		if conn, ok := g.connections[player.ID]; ok {
+			conn.WriteJSON(map[string]interface{}{
+				"type": "dungeon_closed",
+				"data": map[string]interface{}{
+					"instance_id": inst.ID,
+					"reason":      reason,
+					"zone":        back.Zone,
+				},
+			})
+		}
+		g.sendZoneObstaclesLocked(player)
+	}
+
+	for _, mob := range g.mobs {
+		if mob.Zone == inst.ID {
+			g.despawnMobLocked(mob)
+		}
+	}
+	for _, drop := range g.petalDrops {
+		if drop.Zone == inst.ID {
+			g.removePetalDrop(drop, "dungeon_closed")
+		}
+	}
+	for id, projectile := range g.projectiles {
+		if projectile.Zone == inst.ID {
+			delete(g.projectiles, id)
+		}
+	}
+	for id, minion := range g.minions {
+		if minion.Zone == inst.ID {
+			delete(g.minions, id)
+		}
+	}
+
+	delete(g.portals, inst.ExitPortalID)
+	delete(g.navGrids, inst.ID)
+	delete(g.zones, inst.ID)
+	delete(g.dungeons, inst.ID)
+
+	lifetime := now.Sub(inst.Created)
+	stats := &g.dungeonStats
+	stats.Closed[reason]++
+	stats.TotalLifetime += lifetime
+	if lifetime > stats.MaxLifetime {
+		stats.MaxLifetime = lifetime
+	}
+	fmt.Printf("🏚️ Dungeon %s closed (%s) after %s\n", inst.ID, reason, lifetime.Round(time.Second))
+}
+
+// closeAllDungeonsLocked — закрывает все инстансы (перезагрузка контента)
+func (g *Game) closeAllDungeonsLocked(reason string) {
+	now := time.Now()
+	for _, inst := range g.dungeons {
+		g.closeDungeonLocked(inst, reason, now)
+	}
+}
+
+// DungeonMetrics — снимок метрик инстансов
+func (g *Game) DungeonMetrics() DungeonMetrics {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	now := time.Now()
+	stats := g.dungeonStats
+	metrics := DungeonMetrics{
+		Active:             len(g.dungeons),
+		Created:            stats.Created,
+		Completed:          stats.Completed,
+		Closed:             make(map[string]int),
+		MaxLifetimeSeconds: stats.MaxLifetime.Seconds(),
+		Instances:          make([]DungeonInstanceInfo, 0, len(g.dungeons)),
+	}
+	closed := 0
+	for reason, count := range stats.Closed {
+		metrics.Closed[reason] = count
+		closed += count
+	}
+	if closed > 0 {
+		metrics.AvgLifetimeSeconds = stats.TotalLifetime.Seconds() / float64(closed)
+	}
+
+	for _, inst := range g.dungeons {
+		metrics.Instances = append(metrics.Instances, DungeonInstanceInfo{
+			ID:         inst.ID,
+			Dungeon:    inst.Template.ID,
+			Owner:      inst.Owner,
+			Players:    g.dungeonPlayersLocked(inst),
+			Mobs:       g.dungeonMobsLocked(inst),
+			AgeSeconds: now.Sub(inst.Created).Seconds(),
+			Completed:  inst.Completed,
+		})
+	}
+	sort.Slice(metrics.Instances, func(i, j int) bool {
+		return metrics.Instances[i].AgeSeconds > metrics.Instances[j].AgeSeconds
+	})
+	return metrics
+}
+
+// dungeon — шаблон подземелья по ID
+func (m *MapDef) dungeon(id string) *DungeonTemplate {
+	for _, d := range m.Dungeons {
+		if d.ID == id {
+			return d
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"math"
+	"sort"
+)
+
+// Параметры формации лепестков
+const (
+	FormationRotationSpeed = 1.5  // общая угловая скорость кольца (рад/с)
+	ClusterSpread          = 0.18 // угловой разброс лепестков внутри одного кластера (рад)
+)
+
+// formationSlot — одна позиция в кольце; кластерные лепестки делят слот
+type formationSlot struct {
+	petals []*Petal
+}
+
+// RecomputeFormation равномерно расставляет активные лепестки по кольцу.
+// Лепестки с ClusterSize > 1 группируются по типу и редкости и занимают
+// один слот на кластер. Вызывается при любом изменении набора лепестков.
+func (p *Player) RecomputeFormation() {
+	active := p.GetActivePetals()
+	// Стабильный порядок, чтобы формация не "прыгала" между пересчётами
+	sort.Slice(active, func(i, j int) bool { return active[i].ID < active[j].ID })
+
+	slots := make([]*formationSlot, 0, len(active))
+	open := make(map[string]*formationSlot) // незаполненные кластеры по ключу тип+редкость
+
+	for _, petal := range active {
+		clusterSize := PetalConfigs[petal.Type].ClusterSize
+		if clusterSize <= 1 {
+			slots = append(slots, &formationSlot{petals: []*Petal{petal}})
+			continue
+		}
+
+		key := string(petal.Type) + ":" + string(petal.Rarity)
+		slot := open[key]
+		if slot == nil {
+			slot = &formationSlot{}
+			slots = append(slots, slot)
+			open[key] = slot
+		}
+		slot.petals = append(slot.petals, petal)
+		if len(slot.petals) >= clusterSize {
+			delete(open, key)
+		}
+	}
+
+	if len(slots) == 0 {
+		return
+	}
+
+	step := 2 * math.Pi / float64(len(slots))
+	for i, slot := range slots {
+		base := step * float64(i)
+		n := len(slot.petals)
+		for j, petal := range slot.petals {
+			// Центрируем кластер вокруг угла слота
+			petal.SlotAngle = base + (float64(j)-float64(n-1)/2)*ClusterSpread
+		}
+	}
+}
+
+// advanceFormation поворачивает кольцо лепестков игрока
+func (p *Player) advanceFormation(deltaTime float64) {
+	p.FormationAngle += FormationRotationSpeed * deltaTime
+	if p.FormationAngle > 2*math.Pi {
+		p.FormationAngle -= 2 * math.Pi
+	}
+}
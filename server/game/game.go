@@ -14,6 +14,8 @@ import (
 const (
 	PlayerRadius    = 15.0
 	CollisionBuffer = 5.0
+
+	DefaultMaxMobsPerZone = 40 // если в карте для зоны не задан max_mobs
 )
 
 // GameMessage — сообщение между клиентом и сервером
@@ -24,21 +26,35 @@ type GameMessage struct {
 
 // Portal — портал между зонами
 type Portal struct {
-	ID   string
-	X    float64
-	Y    float64
-	To   string // ID портала назначения
-	Zone string
+	ID           string             `json:"id"`
+	X            float64            `json:"x"`
+	Y            float64            `json:"y"`
+	Radius       float64            `json:"radius"`
+	To           string             `json:"to"` // ID портала назначения
+	Zone         string             `json:"zone"`
+	Requirements PortalRequirements `json:"requirements"`
+	Dungeon      string             `json:"dungeon,omitempty"` // ID шаблона подземелья вместо To
 }
 
-// Zone — игровая зона
+// Zone — игровая зона (описывается в файле карты, см. world_map.go)
 type Zone struct {
-	Name  string
-	MinX  float64
-	MaxX  float64
-	MinY  float64
-	MaxY  float64
-	Color string
+	Name  string  `json:"name"`
+	MinX  float64 `json:"min_x"`
+	MaxX  float64 `json:"max_x"`
+	MinY  float64 `json:"min_y"`
+	MaxY  float64 `json:"max_y"`
+	Color string  `json:"color"`
+	// Obstacles — статические препятствия внутри зоны (см. obstacle.go)
+	Obstacles []*Obstacle `json:"obstacles,omitempty"`
+	// Спавн мобов: распределение редкостей, типы, лимит и аффиксы
+	RarityDistribution map[Rarity]float64 `json:"rarity,omitempty"`
+	MobTypes           []MobType          `json:"mob_types,omitempty"`
+	MaxMobs            int                `json:"max_mobs,omitempty"`
+	Affixes            *AffixZoneSettings `json:"affixes,omitempty"`
+	// Заполняются загрузчиком карты
+	SpawnRegions []*SpawnRegion `json:"-"`
+	POIs         []*POI         `json:"-"`
+	Regions      []*Region      `json:"-"`
 }
 
 // Game — основной игровой мир
@@ -56,10 +72,43 @@ type Game struct {
 
 	petalDrops map[string]*PetalDrop // Добавить это поле
 	petals     map[string]*Petal     // И это
+	dropSeq    uint64                // счётчик для уникальных ID дропов
+	entitySeq  uint64                // счётчик для ID снарядов, союзников и порождённых мобов
+	lootRng    *rand.Rand            // ГСЧ для таблиц дропа (используется под g.mu)
+
+	projectiles map[string]*Projectile // снаряды лепестков
+	minions     map[string]*Minion     // союзники, призванные лепестками
+
+	aiWatchers map[string]string     // playerID → mobID для отладки ИИ
+	mobGroups  map[string]*MobGroup  // группы мобов, заспавненных вместе
+	bosses     map[string]*bossState // состояние энкаунтеров по ID
+
+	partyInvites map[string]*partyInvite // ожидающие приглашения по ID приглашённого
+
+	dungeons     map[string]*dungeonInstance // инстансы подземелий по имени зоны
+	dungeonSeq   uint64                      // счётчик для ID инстансов
+	dungeonStats dungeonStats                // метрики закрытых инстансов
+
+	navGrids  map[string]*navGrid    // сетки проходимости зон с препятствиями
+	pathCache map[navKey]*cachedPath // общие маршруты мобов (см. navigation.go)
+
+	mapDef    *MapDef // карта, на которой идёт игра
+	spawnZone string  // зона появления и возрождения игроков
+
+	progress *progressSaver // сохранение прогресса игроков (см. progress_store.go)
+
+	done      chan struct{} // закрывается в Close и останавливает игровые циклы
+	closeOnce sync.Once
 }
 
-// NewGame создаёт новый игровой мир
+// NewGame создаёт новый игровой мир на карте по умолчанию
 func NewGame() *Game {
+	m, _ := lookupMap(DefaultMapName)
+	return NewGameWithMap(m)
+}
+
+// NewGameWithMap создаёт игровой мир на заданной карте
+func NewGameWithMap(m *MapDef) *Game {
 	g := &Game{
 		players:     make(map[string]*Player),
 		mobs:        make(map[string]*Mob),
@@ -70,10 +119,29 @@ func NewGame() *Game {
 
 		petalDrops: make(map[string]*PetalDrop),
 		petals:     make(map[string]*Petal),
+		lootRng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+
+		projectiles: make(map[string]*Projectile),
+		minions:     make(map[string]*Minion),
+
+		aiWatchers: make(map[string]string),
+		mobGroups:  make(map[string]*MobGroup),
+		bosses:     make(map[string]*bossState),
+		dungeons:   make(map[string]*dungeonInstance),
+
+		partyInvites: make(map[string]*partyInvite),
+
+		dungeonStats: dungeonStats{Closed: make(map[string]int)},
+
+		navGrids:  make(map[string]*navGrid),
+		pathCache: make(map[navKey]*cachedPath),
+
+		progress: newProgressSaver(),
+		done:     make(chan struct{}),
 	}
 
-	g.initZones()
-	g.initPortals()
+	g.applyMapLocked(m)
+	g.initNavigation()
 
 	// Запускаем игровые циклы
 	go g.synchronizeGameState()
@@ -81,65 +149,65 @@ func NewGame() *Game {
 	go g.mobSpawnLoop()
 	go g.collisionLoop()
 	go g.petalSystemLoop()
+	go g.statusEffectLoop()
+	go g.bossLoop()
+	go g.dungeonLoop()
+	go g.regionLoop()
+	go g.progressLoop()
 
 	return g
 }
 
-func (g *Game) collisionLoop() {
-	ticker := time.NewTicker(100 * time.Millisecond) // 10 раз в секунду
-	defer ticker.Stop()
+// Close останавливает игровые циклы и закрывает соединения игроков.
+// Повторный вызов ничего не делает.
+func (g *Game) Close() {
+	g.closeOnce.Do(func() {
+		close(g.done)
 
-	for range ticker.C {
-		g.checkCollisions()
-	}
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		for _, conn := range g.connections {
+			conn.Close()
+		}
+	})
 }
 
-// initZones — инициализация зон
-func (g *Game) initZones() {
-	g.zones["common"] = &Zone{MinX: 0, MaxX: 6000, MinY: 0, MaxY: 3000, Color: "#666666"}
-	g.zones["uncommon"] = &Zone{MinX: 7000, MaxX: 13000, MinY: 0, MaxY: 3000, Color: "#00FF00"}
-	g.zones["rare"] = &Zone{MinX: 14000, MaxX: 20000, MinY: 0, MaxY: 3000, Color: "#0088FF"}
-	g.zones["epic"] = &Zone{MinX: 21000, MaxX: 27000, MinY: 0, MaxY: 3000, Color: "#FF00FF"}
-	g.zones["legendary"] = &Zone{MinX: 28000, MaxX: 34000, MinY: 0, MaxY: 3000, Color: "#FFAA00"}
-
-	g.worldWidth = 34000.0
-	g.worldHeight = 3000.0
-	fmt.Println("✅ Zones initialized")
+// tick ждёт следующего тика цикла; false — игра остановлена
+func (g *Game) tick(ticker *time.Ticker) bool {
+	select {
+	case <-ticker.C:
+		return true
+	case <-g.done:
+		return false
+	}
 }
 
-// initPortals — инициализация порталов
-func (g *Game) initPortals() {
-	portals := []*Portal{
-		{"P1", 5800, 1500, "P2", "common"},
-		{"P2", 7200, 1500, "P1", "uncommon"},
-		{"P3", 12800, 1500, "P4", "uncommon"},
-		{"P4", 14200, 1500, "P3", "rare"},
-		{"P5", 19800, 1500, "P6", "rare"},
-		{"P6", 21200, 1500, "P5", "epic"},
-		{"P7", 26800, 1500, "P8", "epic"},
-		{"P8", 28200, 1500, "P7", "legendary"},
-	}
+func (g *Game) collisionLoop() {
+	ticker := time.NewTicker(100 * time.Millisecond) // 10 раз в секунду
+	defer ticker.Stop()
 
-	for _, p := range portals {
-		g.portals[p.ID] = p
+	for g.tick(ticker) {
+		g.checkCollisions()
 	}
-	fmt.Println("✅ Portals initialized")
 }
 
 // AddPlayer — добавляет игрока в игру
-func (g *Game) AddPlayer(conn *websocket.Conn, userID, username string) *Player {
+func (g *Game) AddPlayer(conn *websocket.Conn, userID, username string, progress PlayerProgress) *Player {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
 	playerID := fmt.Sprintf("p_%d", time.Now().UnixNano())
-	spawnX, spawnY := g.findSafeSpawnPosition("common", playerID)
+	spawnX, spawnY := g.findSafeSpawnPosition(g.spawnZone, playerID)
 	color := g.colors[rand.Intn(len(g.colors))]
 
 	player := NewPlayer(playerID, userID, username, spawnX, spawnY, color)
-	player.CurrentZone = "common"
+	player.CurrentZone = g.spawnZone
+	player.applyProgress(progress)
+	player.UnlockZone(g.spawnZone)
 
 	g.players[playerID] = player
 	g.connections[playerID] = conn
+	g.sendWorldInfoLocked(playerID)
 
 	fmt.Printf("🆕 Player %s joined\n", playerID)
 	return player
@@ -154,10 +222,32 @@ func (g *Game) RemovePlayer(playerID string) {
 		conn.Close()
 		delete(g.connections, playerID)
 	}
+	// Сбрасываем лепестки вместе с их таймерами восстановления
+	if player, ok := g.players[playerID]; ok {
+		player.RemoveAllPetals()
+		g.leavePartyLocked(player)
+		g.saveProgressLocked(player)
+	}
 	delete(g.players, playerID)
+	delete(g.aiWatchers, playerID)
+	delete(g.partyInvites, playerID)
 	fmt.Printf("👋 Player %s left\n", playerID)
 }
 
+// SetPlayerStance — меняет стойку игрока (атака / защита / нейтральная)
+func (g *Game) SetPlayerStance(playerID string, stance Stance) {
+	if !IsValidStance(stance) {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if player := g.players[playerID]; player != nil {
+		player.Stance = stance
+	}
+}
+
 // MovePlayer — обрабатывает движение игрока
 func (g *Game) MovePlayer(playerID string, dx, dy float64) {
 	g.mu.Lock()
@@ -175,10 +265,12 @@ func (g *Game) MovePlayer(playerID string, dx, dy float64) {
 		dy /= length
 	}
 
-	newX := player.X + dx*player.Speed
-	newY := player.Y + dy*player.Speed
+	speed := player.Speed * player.Effects.SpeedMultiplier()
+	newX := player.X + dx*speed
+	newY := player.Y + dy*speed
 
-	// Ограничиваем зоной
+	// Ограничиваем зоной (на бесшовной карте сначала переходим в соседнюю)
+	g.crossZoneBoundaryLocked(player, newX, newY)
 	newX, newY = g.constrainToZone(player, newX, newY)
 
 	// Избегаем других игроков
@@ -203,6 +295,9 @@ func (g *Game) MovePlayer(playerID string, dx, dy float64) {
 		}
 	}
 
+	// Не даём пройти сквозь препятствия
+	newX, newY = g.resolveObstaclesLocked(player.CurrentZone, newX, newY, player.Radius, false)
+
 	player.X = newX
 	player.Y = newY
 	g.checkPortalInteraction(player)
@@ -212,9 +307,9 @@ func (g *Game) MovePlayer(playerID string, dx, dy float64) {
 func (g *Game) constrainToZone(player *Player, x, y float64) (float64, float64) {
 	zone := g.zones[player.CurrentZone]
 	if zone == nil {
-		// Если зона не найдена — телепортируем в common
-		player.CurrentZone = "common"
-		zone = g.zones["common"]
+		// Если зона не найдена — телепортируем в зону появления
+		player.CurrentZone = g.spawnZone
+		zone = g.zones[g.spawnZone]
 	}
 
 	if x < zone.MinX {
@@ -240,9 +335,12 @@ func (g *Game) checkPortalInteraction(player *Player) {
 	}
 
 	for _, portal := range g.portals {
+		if portal.Zone != player.CurrentZone {
+			continue
+		}
 		dx := player.X - portal.X
 		dy := player.Y - portal.Y
-		if dx*dx+dy*dy <= 100*100 { // радиус 100 (без sqrt!)
+		if dx*dx+dy*dy <= portal.Radius*portal.Radius { // без sqrt!
 			g.teleportPlayer(player, portal)
 			break
 		}
@@ -251,10 +349,18 @@ func (g *Game) checkPortalInteraction(player *Player) {
 
 // teleportPlayer — телепортирует игрока
 func (g *Game) teleportPlayer(player *Player, fromPortal *Portal) {
+	if fromPortal.Dungeon != "" {
+		g.enterDungeonLocked(player, fromPortal)
+		return
+	}
+
 	toPortal := g.portals[fromPortal.To]
 	if toPortal == nil {
 		return
 	}
+	if !g.canEnterZoneLocked(player, fromPortal, toPortal) {
+		return
+	}
 
 	player.X = toPortal.X
 	player.Y = toPortal.Y
@@ -272,6 +378,7 @@ func (g *Game) teleportPlayer(player *Player, fromPortal *Portal) {
 	if conn, ok := g.connections[player.ID]; ok {
 		conn.WriteJSON(notif)
 	}
+	g.sendZoneObstaclesLocked(player)
 
 	fmt.Printf("🌀 %s teleported to %s zone\n", player.ID, toPortal.Zone)
 }
@@ -279,11 +386,20 @@ func (g *Game) teleportPlayer(player *Player, fromPortal *Portal) {
 // findSafeSpawnPosition — ищет безопасную позицию для спавна
 func (g *Game) findSafeSpawnPosition(zoneName, excludeID string) (float64, float64) {
 	zone := g.zones[zoneName]
+	minX, maxX, minY, maxY := zone.MinX, zone.MaxX, zone.MinY, zone.MaxY
+	// Если в зоне есть безопасная область — появляемся в ней
+	safeRegion := zone.safeRegion()
+	if safeRegion != nil {
+		minX, maxX, minY, maxY = safeRegion.bounds()
+	}
 	for i := 0; i < 20; i++ {
-		x := zone.MinX + rand.Float64()*(zone.MaxX-zone.MinX)
-		y := zone.MinY + rand.Float64()*(zone.MaxY-zone.MinY)
+		x := minX + rand.Float64()*(maxX-minX)
+		y := minY + rand.Float64()*(maxY-minY)
+		if safeRegion != nil && !safeRegion.Contains(x, y) {
+			continue
+		}
 
-		safe := true
+		safe := !g.blockedLocked(zoneName, x, y, PlayerRadius*2)
 		for _, p := range g.players {
 			if p.ID == excludeID {
 				continue
@@ -308,7 +424,7 @@ func (g *Game) synchronizeGameState() {
 	ticker := time.NewTicker(16 * time.Millisecond)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for g.tick(ticker) {
 		g.broadcastGameState()
 	}
 }
@@ -325,7 +441,7 @@ func (g *Game) broadcastGameState() {
 
 		zone := player.CurrentZone
 		if zone == "" {
-			zone = "common"
+			zone = g.spawnZone
 		}
 
 		// Фильтруем игроков в зоне (теперь с петалами)
@@ -333,27 +449,47 @@ func (g *Game) broadcastGameState() {
 
 		petalDropsInZone := make(map[string]*PetalDrop)
 		for id, drop := range g.petalDrops {
-			if drop.Zone == zone {
+			if g.visibleFromZoneLocked(zone, drop.Zone, drop.X, drop.Y) {
 				petalDropsInZone[id] = drop
 			}
 		}
 
 		// Петалы текущего игрока (для отдельного управления)
 		playerPetals := make(map[string]*Petal)
+		now := time.Now()
 		if player.Petals != nil {
 			for id, petal := range player.Petals {
 				playerPetals[id] = &Petal{
-					ID:        petal.ID,
-					Type:      petal.Type,
-					Health:    petal.Health,
-					MaxHealth: petal.MaxHealth,
-					X:         petal.X,
-					Y:         petal.Y,
-					IsActive:  petal.IsActive,
+					ID:             petal.ID,
+					Type:           petal.Type,
+					Rarity:         petal.Rarity,
+					Health:         petal.Health,
+					MaxHealth:      petal.MaxHealth,
+					X:              petal.X,
+					Y:              petal.Y,
+					IsActive:       petal.IsActive,
+					OrbitRadius:    petal.OrbitRadius,
+					Angle:          petal.Angle,
+					ReloadProgress: petal.GetReloadProgress(now),
+					Effects:        petal.Effects.Snapshot(now),
 				}
 			}
 		}
 
+		projectilesInZone := make(map[string]*Projectile)
+		for id, projectile := range g.projectiles {
+			if g.visibleFromZoneLocked(zone, projectile.Zone, projectile.X, projectile.Y) {
+				projectilesInZone[id] = projectile
+			}
+		}
+
+		minionsInZone := make(map[string]*Minion)
+		for id, minion := range g.minions {
+			if g.visibleFromZoneLocked(zone, minion.Zone, minion.X, minion.Y) {
+				minionsInZone[id] = minion
+			}
+		}
+
 		state := map[string]interface{}{
 			"type":        "state",
 			"players":     playersInZone, // ← Теперь содержит петалы всех игроков в зоне
@@ -364,6 +500,8 @@ func (g *Game) broadcastGameState() {
 			"yourZone":    zone,
 			"petalDrops":  petalDropsInZone,
 			"petals":      playerPetals, // ← Петалы текущего игрока (для обратной совместимости)
+			"projectiles": projectilesInZone,
+			"minions":     minionsInZone,
 		}
 
 		_ = conn.WriteJSON(state)
@@ -375,7 +513,7 @@ func (g *Game) mobSpawnLoop() {
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for g.tick(ticker) {
 		g.spawnMobsIfNeeded()
 	}
 }
@@ -385,15 +523,21 @@ func (g *Game) spawnMobsIfNeeded() {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	maxMobsPerZone := 40
 	mobCount := make(map[string]int)
 	for _, mob := range g.mobs {
-		mobCount[mob.Zone]++
+		if mob.BossID == "" {
+			mobCount[mob.Zone]++
+		}
 	}
 
-	mobTypes := []MobType{MobTypeOrc, MobTypeWolf, MobTypeGoblin}
-
 	for zoneName, zone := range g.zones {
+		if g.dungeons[zoneName] != nil {
+			continue // мобы инстанса появляются один раз при создании
+		}
+		maxMobsPerZone := zone.MaxMobs
+		if maxMobsPerZone == 0 {
+			maxMobsPerZone = DefaultMaxMobsPerZone
+		}
 		current := mobCount[zoneName]
 		if current >= maxMobsPerZone {
 			continue
@@ -406,18 +550,36 @@ func (g *Game) spawnMobsIfNeeded() {
 
 		for spawned < need && attempts < maxAttempts {
 			attempts++
+
+			// Область спавна из карты (или вся зона) задаёт место и типы мобов
+			region := zone.pickSpawnRegion()
+			minX, maxX, minY, maxY := zone.MinX, zone.MaxX, zone.MinY, zone.MaxY
+			if region != nil {
+				minX, maxX, minY, maxY = region.MinX, region.MaxX, region.MinY, region.MaxY
+			}
+			mobTypes := zone.spawnableMobTypes(region)
 			mobType := mobTypes[rand.Intn(len(mobTypes))]
 			count := rand.Intn(3) + 1
 			if spawned+count > need {
 				count = need - spawned
 			}
 
+			// Группа спавнится кучкой вокруг общего центра
+			centerX := minX + rand.Float64()*(maxX-minX)
+			centerY := minY + rand.Float64()*(maxY-minY)
+			var group *MobGroup
+			if count >= MinGroupSize {
+				group = g.newMobGroupLocked(mobType, zoneName)
+			}
+
 			for i := 0; i < count; i++ {
-				x := zone.MinX + rand.Float64()*(zone.MaxX-zone.MinX)
-				y := zone.MinY + rand.Float64()*(zone.MaxY-zone.MinY)
+				x := centerX + (rand.Float64()*2-1)*GroupSpawnSpread
+				y := centerY + (rand.Float64()*2-1)*GroupSpawnSpread
+				x = math.Max(minX, math.Min(maxX, x))
+				y = math.Max(minY, math.Min(maxY, y))
 
-				// Проверка: далеко ли от игроков?
-				safe := true
+				// Проверка: далеко ли от игроков и не внутри ли препятствия?
+				safe := !g.blockedLocked(zoneName, x, y, MobConfigs[mobType].Radius*2) && !g.inSafeRegionLocked(zoneName, x, y)
 				for _, p := range g.players {
 					dx := x - p.X
 					dy := y - p.Y
@@ -429,11 +591,23 @@ func (g *Game) spawnMobsIfNeeded() {
 
 				if safe {
 					mobID := fmt.Sprintf("mob_%s_%d", zoneName, time.Now().UnixNano())
-					mob := NewMob(mobID, mobType, x, y, zoneName)
+					mob := newMobWithRarity(mobID, mobType, zone.rollRarity(), x, y, zoneName)
+					mob.applyAffixes(rollAffixesWith(zone.affixSettings()))
 					g.mobs[mobID] = mob
+					if group != nil {
+						group.addMember(mob)
+					}
 					spawned++
 				}
 			}
+
+			// Если заспавнить удалось слишком мало — группы не будет
+			if group != nil && len(group.Members) < MinGroupSize {
+				for _, id := range group.Members {
+					g.mobs[id].GroupID = ""
+				}
+				delete(g.mobGroups, group.ID)
+			}
 		}
 	}
 }
@@ -443,7 +617,7 @@ func (g *Game) mobBehaviorLoop() {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for g.tick(ticker) {
 		g.UpdateMobs() // предполагается, что UpdateMobs использует g.mu
 	}
 }
@@ -460,12 +634,12 @@ func (g *Game) GetGameState(playerID string) map[string]interface{} {
 
 	zone := player.CurrentZone
 	if zone == "" {
-		zone = "common"
+		zone = g.spawnZone
 	}
 
 	playersInZone, mobsInZone := g.filterByZone(zone)
 
-	return map[string]interface{}{
+	state := map[string]interface{}{
 		"type":        "state",
 		"players":     playersInZone,
 		"mobs":        mobsInZone,
@@ -473,33 +647,51 @@ func (g *Game) GetGameState(playerID string) map[string]interface{} {
 		"worldWidth":  g.worldWidth,
 		"worldHeight": g.worldHeight,
 		"yourZone":    zone,
+		"map":         g.mapDef.Name,
+	}
+	// Зона могла исчезнуть (закрытое подземелье, перезагрузка контента)
+	if z, ok := g.zones[zone]; ok {
+		state["obstacles"] = z.Obstacles
+		state["pois"] = z.POIs
+		state["regions"] = z.Regions
 	}
+	return state
 }
 
-// filterByZone — вспомогательная функция (вызывается только под RLock)
+// filterByZone — вспомогательная функция (вызывается только под RLock).
+// На бесшовной карте захватывает и соседей у границы зоны.
 func (g *Game) filterByZone(zone string) (map[string]*Player, map[string]*Mob) {
+	now := time.Now()
 	players := make(map[string]*Player)
 	for id, p := range g.players {
-		if p.CurrentZone == zone {
+		if g.visibleFromZoneLocked(zone, p.CurrentZone, p.X, p.Y) {
 			players[id] = &Player{
-				ID:        p.ID,
-				UserID:    p.UserID,
-				Username:  p.Username,
-				X:         p.X,
-				Y:         p.Y,
-				Color:     p.Color,
-				Speed:     p.Speed,
-				Radius:    p.Radius,
-				Health:    p.Health,
-				MaxHealth: p.MaxHealth,
-				Petals:    p.GetPetalsForSerialization(),
+				ID:          p.ID,
+				UserID:      p.UserID,
+				Username:    p.Username,
+				PartyID:     p.PartyID,
+				Stance:      p.Stance,
+				X:           p.X,
+				Y:           p.Y,
+				Color:       p.Color,
+				Speed:       p.Speed,
+				Radius:      p.Radius,
+				Health:      p.Health,
+				MaxHealth:   p.MaxHealth,
+				Petals:      p.GetPetalsForSerialization(),
+				Effects:     p.Effects.Snapshot(now),
+				Level:       p.Level,
+				XP:          p.XP,
+				CurrentZone: p.CurrentZone,
+				Regions:     p.Regions,
+				InSafeZone:  p.InSafeZone,
 			}
 		}
 	}
 
 	mobs := make(map[string]*Mob)
 	for id, m := range g.mobs {
-		if m.Zone == zone {
+		if g.visibleFromZoneLocked(zone, m.Zone, m.X, m.Y) {
 			mobs[id] = &Mob{
 				ID:        m.ID,
 				Type:      m.Type,
@@ -512,6 +704,12 @@ func (g *Game) filterByZone(zone string) (map[string]*Player, map[string]*Mob) {
 				Y:         m.Y,
 				Zone:      m.Zone,
 				Radius:    m.Radius,
+				Effects:   m.Effects.Snapshot(now),
+				GroupID:   m.GroupID,
+				BossID:    m.BossID,
+				Ability:   m.Ability.snapshot(),
+				Stolen:    m.Stolen.snapshot(),
+				Affixes:   m.Affixes,
 			}
 		}
 	}
@@ -560,12 +758,15 @@ func (g *Game) checkCollisions() {
 // handlePlayerMobCollision обрабатывает коллизию игрока и моба
 func (g *Game) handlePlayerMobCollision(player *Player, mob *Mob) {
 	// Моб атакует игрока
-	if mob.CanAttack() {
-		if player.TakeDamageFromMob(mob.Damage) {
+	if mob.CanAttack() && player.CanBeHitByMob() {
+		damage := scaleDamage(mob.Damage, player.StanceIncomingMultiplier())
+		damage = g.absorbPlayerDamage(player, damage)
+		if player.TakeDamageFromMob(damage) {
 			mob.MarkAttack()
+			player.Effects.ApplyAll(MobConfigs[mob.Type].OnHit, mob.ID, time.Now())
 
 			// Отправляем уведомление игроку
-			g.sendDamageNotification(player, mob.Damage)
+			g.sendDamageNotification(player, damage)
 
 			// Проверяем смерть игрока
 			if !player.IsAlive() {
@@ -576,61 +777,134 @@ func (g *Game) handlePlayerMobCollision(player *Player, mob *Mob) {
 
 	// Игрок атакует моба (коллизией)
 	if player.CanAttack() {
-		mob.TakeDamage(player.CollisionDamage)
 		player.MarkAttack()
+		// Если моб умер, выдаётся дроп и отправляется уведомление
+		g.damageMobByPlayer(player, mob, player.CollisionDamage)
+	}
+}
 
-		// Если моб умер, отправляем уведомление
-		if !mob.IsAlive() {
-			var petalType PetalType
-			switch mob.Type {
-			case MobTypeWolf:
-				petalType = PetalTypeWolf
-			case MobTypeGoblin:
-				petalType = PetalTypeGoblin
-			case MobTypeOrc:
-				petalType = PetalTypeOrc
-			default:
-				petalType = PetalTypeGoblin // fallback
-			}
+// damageMobLocked — общий путь урона по мобу: удары игроков, яд, опасные
+// области. attacker может быть nil — тогда угроза не начисляется, а убийство
+// засчитывается игроку с наибольшей угрозой. Возвращает true, если моб умер.
+func (g *Game) damageMobLocked(mob *Mob, damage int, attacker *Player) bool {
+	if !mob.IsAlive() {
+		return false
+	}
+	mob.TakeDamage(damage)
+	if attacker != nil {
+		g.addThreatLocked(mob, attacker.ID, float64(damage)*ThreatPerDamage)
+		g.shareThreatLocked(mob, attacker.ID, float64(damage)*ThreatPerDamage, time.Now())
+		g.recordBossContributionLocked(mob, attacker.ID, damage)
+	}
+	if mob.IsAlive() {
+		return false
+	}
+	g.handleMobKilled(attacker, mob)
+	return true
+}
+
+// handleMobKilled — бросает таблицу дропа убитого моба и уведомляет убийцу.
+// Без убийцы (player == nil) награда достаётся игроку с наибольшей угрозой.
+func (g *Game) handleMobKilled(player *Player, mob *Mob) {
+	if player == nil {
+		player = g.topThreatPlayerLocked(mob)
+	}
 
-			// Создаем дроп лепестка
-			g.createPetalDrop(player.ID, petalType, mob.X, mob.Y)
-			// Отправляем уведомление
+	// Лут босса делится между всеми участниками
+	if mob.BossID != "" {
+		g.handleBossKilledLocked(mob)
+		if player != nil {
 			g.sendMobDeathNotification(player, mob)
 		}
+		return
+	}
+
+	if player != nil {
+		drops := RollLoot(g.lootRng, mob.Type, mob.Rarity)
+		drops = append(drops, g.rollAffixLootLocked(mob)...)
+		g.dropLootLocked(player.ID, mob, drops)
+		g.grantXPLocked(player, mobXP(mob))
+	}
+	g.recordDungeonKillLocked(mob)
+	if player != nil {
+		g.sendMobDeathNotification(player, mob)
+	}
+}
+
+// dropLootLocked — создаёт дропы для владельца вокруг места смерти моба
+func (g *Game) dropLootLocked(ownerID string, mob *Mob, drops []LootDrop) {
+	for i, drop := range drops {
+		// Раскладываем несколько дропов веером вокруг места смерти
+		x, y := mob.X, mob.Y
+		if len(drops) > 1 {
+			angle := 2 * math.Pi * float64(i) / float64(len(drops))
+			x += math.Cos(angle) * 25
+			y += math.Sin(angle) * 25
+		}
+		g.createPetalDrop(ownerID, drop.Type, drop.Rarity, x, y)
 	}
 }
 
-func (g *Game) createPetalDrop(playerID string, petalType PetalType, x, y float64) {
+func (g *Game) createPetalDrop(playerID string, petalType PetalType, rarity Rarity, x, y float64) {
 	player := g.players[playerID]
 	if player == nil {
 		return
 	}
 
+	g.dropSeq++
 	drop := &PetalDrop{
-		ID:       fmt.Sprintf("drop_%d", time.Now().UnixNano()),
-		Type:     petalType,
-		X:        x,
-		Y:        y,
-		OwnerID:  playerID,
-		Zone:     player.CurrentZone, // ← Установите зону
-		Created:  time.Now(),
-		Lifetime: 30 * time.Second,
+		ID:          fmt.Sprintf("drop_%d_%d", time.Now().UnixNano(), g.dropSeq),
+		Type:        petalType,
+		Rarity:      rarity,
+		X:           x,
+		Y:           y,
+		OwnerID:     playerID,
+		Zone:        player.CurrentZone, // ← Установите зону
+		Created:     time.Now(),
+		Lifetime:    DropSettings.Lifetime,
+		OwnerWindow: DropSettings.OwnerWindow,
+	}
+	if DropSettings.PartyShared {
+		drop.PartyID = player.PartyID
 	}
 
 	g.petalDrops[drop.ID] = drop
 
-	// Отправляем уведомление
-	if conn, ok := g.connections[playerID]; ok {
-		conn.WriteJSON(map[string]interface{}{
-			"type": "petal_drop_created",
-			"data": map[string]interface{}{
-				"id":   drop.ID,
-				"type": drop.Type,
-				"x":    drop.X,
-				"y":    drop.Y,
-			},
-		})
+	// Уведомляем всех игроков в зоне
+	g.broadcastToZoneLocked(drop.Zone, map[string]interface{}{
+		"type": "petal_drop_created",
+		"data": map[string]interface{}{
+			"id":       drop.ID,
+			"type":     drop.Type,
+			"rarity":   drop.Rarity,
+			"x":        drop.X,
+			"y":        drop.Y,
+			"owner_id": drop.OwnerID,
+			"party_id": drop.PartyID,
+			"free_at":  drop.FreeAt().UnixMilli(),
+		},
+	})
+}
+
+// removePetalDrop — удаляет дроп и уведомляет зону (вызывается под g.mu)
+func (g *Game) removePetalDrop(drop *PetalDrop, reason string) {
+	delete(g.petalDrops, drop.ID)
+
+	g.broadcastToZoneLocked(drop.Zone, map[string]interface{}{
+		"type": "petal_drop_removed",
+		"data": map[string]interface{}{
+			"id":     drop.ID,
+			"reason": reason,
+		},
+	})
+}
+
+// broadcastToZoneLocked — отправляет сообщение всем игрокам зоны (вызывается под g.mu)
+func (g *Game) broadcastToZoneLocked(zone string, msg interface{}) {
+	for id, conn := range g.connections {
+		if player := g.players[id]; player != nil && player.CurrentZone == zone {
+			conn.WriteJSON(msg)
+		}
 	}
 }
 
@@ -655,11 +929,21 @@ func (g *Game) removeDeadMobs() {
 	}
 
 	for _, id := range deadMobs {
-		delete(g.mobs, id)
+		g.triggerAffixDeathLocked(g.mobs[id], time.Now())
+		g.despawnMobLocked(g.mobs[id])
 		fmt.Printf("☠️ Mob %s died and removed\n", id)
 	}
 }
 
+// despawnMobLocked — убирает моба из мира: возвращает украденный лепесток
+// и выводит моба из группы. Через неё удаляются и погибшие мобы, и мобы
+// исчезнувших зон.
+func (g *Game) despawnMobLocked(mob *Mob) {
+	g.releaseStolenPetalLocked(mob, time.Now(), true)
+	g.removeFromGroupLocked(mob)
+	delete(g.mobs, mob.ID)
+}
+
 // sendDamageNotification отправляет уведомление о получении урона
 func (g *Game) sendDamageNotification(player *Player, damage int) {
 	if conn, ok := g.connections[player.ID]; ok {
@@ -694,7 +978,7 @@ func (g *Game) sendMobDeathNotification(player *Player, mob *Mob) {
 			"data": map[string]interface{}{
 				"mob_type": mob.Type,
 				"rarity":   mob.Rarity,
-				"xp":       mob.MaxHealth / 2, // Простая формула опыта
+				"xp":       mobXP(mob),
 			},
 		})
 	}
@@ -711,8 +995,9 @@ func (g *Game) RespawnPlayer(playerID string) {
 	}
 
 	// Находим безопасную позицию для возрождения
-	x, y := g.findSafeSpawnPosition("common", playerID)
-	player.Respawn(x, y)
+	x, y := g.findSafeSpawnPosition(g.spawnZone, playerID)
+	player.Respawn(x, y, g.spawnZone)
+	g.sendZoneObstaclesLocked(player)
 
 	// Отправляем уведомление о возрождении
 	if conn, ok := g.connections[playerID]; ok {
@@ -741,11 +1026,11 @@ func (g *Game) petalSystemLoop() {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for g.tick(ticker) {
 		g.updatePetals()
 		g.checkPetalDrops()
 		g.checkPetalCollisions()
-		g.checkPetalHealing()
+		g.updatePetalBehaviors()
 	}
 }
 
@@ -755,11 +1040,23 @@ func (g *Game) updatePetals() {
 
 	deltaTime := 0.1 // 100ms в секундах
 
+	now := time.Now()
+
 	for _, player := range g.players {
+		player.advanceFormation(deltaTime)
+
 		for _, petal := range player.Petals {
+			// Мёртвые игроки не восстанавливают лепестки
+			if petal.ReloadDone(now) && player.IsAlive() {
+				g.respawnPetal(player, petal)
+			}
+
 			if petal.IsActive {
 				// Обновляем позицию лепестка
-				petalX, petalY := petal.UpdatePosition(player.X, player.Y, deltaTime)
+				petalX, petalY := petal.UpdatePosition(player.X, player.Y, player.FormationAngle, player.StanceRadiusMultiplier(), deltaTime)
+
+				// Лепесток огибает препятствия (10 — радиус лепестка, как в коллизиях)
+				petalX, petalY = g.resolveObstaclesLocked(player.CurrentZone, petalX, petalY, 10, true)
 
 				// Сохраняем позицию для коллизий
 				petal.X = petalX
@@ -774,22 +1071,22 @@ func (g *Game) checkPetalDrops() {
 	defer g.mu.Unlock()
 
 	// Проверяем просроченные дропы
-	expiredDrops := make([]string, 0)
-	for id, drop := range g.petalDrops {
+	expiredDrops := make([]*PetalDrop, 0)
+	for _, drop := range g.petalDrops {
 		if drop.IsExpired() {
-			expiredDrops = append(expiredDrops, id)
+			expiredDrops = append(expiredDrops, drop)
 		}
 	}
 
 	// Удаляем просроченные дропы
-	for _, id := range expiredDrops {
-		delete(g.petalDrops, id)
+	for _, drop := range expiredDrops {
+		g.removePetalDrop(drop, "expired")
 	}
 
 	// Проверяем подбор дропов игроками
 	for _, player := range g.players {
 		for _, drop := range g.petalDrops {
-			if drop.CanBePickedBy(player.ID) && player.IsAlive() {
+			if drop.Zone == player.CurrentZone && drop.CanBePickedBy(player) && player.IsAlive() {
 				distance := player.DistanceTo(drop.X, drop.Y)
 				if distance < 50 { // Радиус подбора
 					g.pickUpPetal(player, drop)
@@ -802,22 +1099,23 @@ func (g *Game) checkPetalDrops() {
 
 func (g *Game) pickUpPetal(player *Player, drop *PetalDrop) {
 	// Добавляем лепесток игроку
-	player.AddPetal(drop.Type)
+	player.AddPetal(drop.Type, drop.Rarity)
 
 	// Удаляем дроп
-	delete(g.petalDrops, drop.ID)
+	g.removePetalDrop(drop, "picked_up")
 
 	// Отправляем уведомление
 	if conn, ok := g.connections[player.ID]; ok {
 		conn.WriteJSON(map[string]interface{}{
 			"type": "petal_picked_up",
 			"data": map[string]interface{}{
-				"type": drop.Type,
+				"type":   drop.Type,
+				"rarity": drop.Rarity,
 			},
 		})
 	}
 
-	fmt.Printf("🎯 Player %s picked up %s petal\n", player.ID, drop.Type)
+	fmt.Printf("🎯 Player %s picked up %s %s petal\n", player.ID, drop.Rarity, drop.Type)
 }
 
 func (g *Game) checkPetalCollisions() {
@@ -846,35 +1144,24 @@ func (g *Game) checkPetalCollisions() {
 }
 
 func (g *Game) handlePetalMobCollision(petal *Petal, mob *Mob) {
+	// Находим владельца лепестка (его стойка влияет на урон)
+	player := g.players[petal.OwnerID]
+	damageMult, incomingMult := 1.0, 1.0
+	if player != nil {
+		damageMult = player.StanceDamageMultiplier()
+		incomingMult = player.StanceIncomingMultiplier()
+	}
+
 	// Лепесток атакует моба
 	if petal.CanAttack() {
-		mob.TakeDamage(petal.Damage)
+		damage := scaleDamage(petal.Damage, damageMult)
 		petal.LastAttack = time.Now()
+		mob.Effects.ApplyAll(PetalConfigs[petal.Type].OnHit, petal.OwnerID, petal.LastAttack)
 
-		// Если моб умер, засчитываем килл игроку и создаем дроп
-		if !mob.IsAlive() {
-			// Находим владельца лепестка
-			player := g.players[petal.OwnerID]
-			if player != nil {
-				// Создаем дроп лепестка для игрока
-				var petalType PetalType
-				switch mob.Type {
-				case MobTypeWolf:
-					petalType = PetalTypeWolf
-				case MobTypeGoblin:
-					petalType = PetalTypeGoblin
-				case MobTypeOrc:
-					petalType = PetalTypeOrc
-				default:
-					petalType = PetalTypeGoblin // fallback
-				}
-
-				g.createPetalDrop(petal.OwnerID, petalType, mob.X, mob.Y)
-
-				// Отправляем уведомление об убийстве
-				g.sendMobDeathNotification(player, mob)
-			}
+		// Засчитываем урон и килл владельцу (дроп создаётся в damageMobLocked)
+		killed := g.damageMobLocked(mob, damage, player)
 
+		if killed {
 			// Также отправляем специальное уведомление о убийстве петалом
 			if conn, ok := g.connections[petal.OwnerID]; ok {
 				conn.WriteJSON(map[string]interface{}{
@@ -882,7 +1169,7 @@ func (g *Game) handlePetalMobCollision(petal *Petal, mob *Mob) {
 					"data": map[string]interface{}{
 						"mob_type":   mob.Type,
 						"petal_type": petal.Type,
-						"xp":         mob.MaxHealth / 2, // Та же формула опыта
+						"xp":         mobXP(mob),
 					},
 				})
 			}
@@ -891,8 +1178,9 @@ func (g *Game) handlePetalMobCollision(petal *Petal, mob *Mob) {
 
 	// Моб атакует лепесток
 	if mob.CanAttack() {
-		petal.TakeDamage(mob.Damage)
+		petal.TakeDamage(scaleDamage(mob.Damage, incomingMult))
 		mob.MarkAttack()
+		petal.Effects.ApplyAll(MobConfigs[mob.Type].OnHit, mob.ID, time.Now())
 
 		// Если лепесток уничтожен
 		if !petal.IsActive {
@@ -902,34 +1190,36 @@ func (g *Game) handlePetalMobCollision(petal *Petal, mob *Mob) {
 }
 
 func (g *Game) handlePetalDestroyed(petal *Petal) {
-	// Запускаем таймер восстановления
-	go func() {
-		time.Sleep(2 * time.Second)
-		g.mu.Lock()
-		defer g.mu.Unlock()
+	// Сдвигаем оставшиеся лепестки, чтобы закрыть дыру в кольце
+	if player, ok := g.players[petal.OwnerID]; ok {
+		player.RecomputeFormation()
+	}
 
-		if player, ok := g.players[petal.OwnerID]; ok {
-			if existingPetal, ok := player.Petals[petal.ID]; ok {
-				existingPetal.Respawn()
-
-				// Уведомляем игрока о восстановлении
-				if conn, ok := g.connections[petal.OwnerID]; ok {
-					conn.WriteJSON(map[string]interface{}{
-						"type": "petal_respawned",
-						"data": map[string]interface{}{
-							"petal_id": petal.ID,
-							"type":     petal.Type,
-						},
-					})
-				}
-			}
-		}
-	}()
+	// Запускаем таймер восстановления (обрабатывается в updatePetals)
+	petal.StartReload(time.Now())
 
 	// Отправляем уведомление об уничтожении
 	if conn, ok := g.connections[petal.OwnerID]; ok {
 		conn.WriteJSON(map[string]interface{}{
 			"type": "petal_destroyed",
+			"data": map[string]interface{}{
+				"petal_id":  petal.ID,
+				"type":      petal.Type,
+				"reload_ms": petal.ReloadTime.Milliseconds(),
+			},
+		})
+	}
+}
+
+// respawnPetal — восстанавливает лепесток после перезарядки (вызывается под g.mu)
+func (g *Game) respawnPetal(player *Player, petal *Petal) {
+	petal.Respawn()
+	player.RecomputeFormation()
+
+	// Уведомляем игрока о восстановлении
+	if conn, ok := g.connections[player.ID]; ok {
+		conn.WriteJSON(map[string]interface{}{
+			"type": "petal_respawned",
 			"data": map[string]interface{}{
 				"petal_id": petal.ID,
 				"type":     petal.Type,
@@ -944,51 +1234,25 @@ func (p *Player) GetPetalsForSerialization() map[string]*Petal {
 	}
 
 	// Создаем копию для безопасной сериализации
+	now := time.Now()
 	petalsCopy := make(map[string]*Petal)
 	for id, petal := range p.Petals {
 		petalsCopy[id] = &Petal{
-			ID:        petal.ID,
-			Type:      petal.Type,
-			Health:    petal.Health,
-			MaxHealth: petal.MaxHealth,
-			X:         petal.X,
-			Y:         petal.Y,
-			IsActive:  petal.IsActive,
+			ID:             petal.ID,
+			Type:           petal.Type,
+			Rarity:         petal.Rarity,
+			Health:         petal.Health,
+			MaxHealth:      petal.MaxHealth,
+			X:              petal.X,
+			Y:              petal.Y,
+			IsActive:       petal.IsActive,
+			OrbitRadius:    petal.OrbitRadius,
+			Angle:          petal.Angle,
+			Speed:          petal.Speed,
+			ReloadProgress: petal.GetReloadProgress(now),
+			Effects:        petal.Effects.Snapshot(now),
 			// Не копируем чувствительные или временные поля
 		}
 	}
 	return petalsCopy
 }
-
-func (g *Game) checkPetalHealing() {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-
-	now := time.Now()
-
-	for _, player := range g.players {
-		for _, petal := range player.Petals {
-			if petal.CanHeal() && player.Health < player.MaxHealth {
-				// Исцеляем игрока
-				player.Health += petal.HealAmount
-				if player.Health > player.MaxHealth {
-					player.Health = player.MaxHealth
-				}
-
-				petal.LastHeal = now
-
-				// Отправляем уведомление об исцелении
-				if conn, ok := g.connections[player.ID]; ok {
-					conn.WriteJSON(map[string]interface{}{
-						"type": "petal_healed",
-						"data": map[string]interface{}{
-							"petal_id": petal.ID,
-							"amount":   petal.HealAmount,
-							"health":   player.Health,
-						},
-					})
-				}
-			}
-		}
-	}
-}
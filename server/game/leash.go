@@ -0,0 +1,72 @@
+package game
+
+import "time"
+
+// Параметры возврата домой
+const (
+	DefaultLeashRadius = 800.0 // если для типа моба поводок не задан
+	LeashHomeTolerance = 20.0  // на каком расстоянии от точки спавна моб считается дома
+	LeashResetRegen    = 0.25  // доля MaxHealth, восстанавливаемая за секунду при возврате
+)
+
+// leashRadiusFor — радиус поводка по типу и редкости (с откатом на common)
+func leashRadiusFor(mobType MobType, rarity Rarity) float64 {
+	radii := MobConfigs[mobType].LeashRadius
+	radius, ok := radii[rarity]
+	if !ok {
+		radius, ok = radii[RarityCommon]
+	}
+	if !ok || radius <= 0 {
+		return DefaultLeashRadius
+	}
+	return radius
+}
+
+// IsBeyondLeash — ушёл ли моб слишком далеко от точки спавна
+func (m *Mob) IsBeyondLeash() bool {
+	return m.DistanceTo(m.HomeX, m.HomeY) > m.LeashRadius
+}
+
+// updateLeashLocked — уводит моба домой, если его утянули за поводок.
+// Возвращает true, пока моб возвращается (обычное поведение не выполняется).
+func (g *Game) updateLeashLocked(mob *Mob, config MobConfig, now time.Time, deltaTime float64) bool {
+	if mob.State != MobStateReturning {
+		if !mob.IsBeyondLeash() {
+			return false
+		}
+		// Сдаёмся: сбрасываем цель
+		mob.State = MobStateReturning
+		mob.TargetPlayer = ""
+		mob.HelpCalledAt = time.Time{}
+		mob.Ability = nil
+	}
+
+	// Пока моб идёт домой, угроза не копится
+	mob.Threat = nil
+
+	// Восстанавливаемся по пути, чтобы моба нельзя было "кайтить"
+	regen := int(float64(mob.MaxHealth) * LeashResetRegen * deltaTime)
+	if regen < 1 {
+		regen = 1
+	}
+	mob.Health += regen
+	if mob.Health > mob.MaxHealth {
+		mob.Health = mob.MaxHealth
+	}
+
+	if mob.DistanceTo(mob.HomeX, mob.HomeY) <= LeashHomeTolerance {
+		// Дома — возвращаемся к обычному поведению с начального состояния
+		mob.State = MobStateWandering
+		mob.AIState = ""
+		mob.Health = mob.MaxHealth
+		mob.LastMoveTime = now
+		return false
+	}
+
+	mob.TargetX = mob.HomeX
+	mob.TargetY = mob.HomeY
+	if config.ChaseSpeed > 0 {
+		mob.Speed = chaseSpeedFor(mob, config)
+	}
+	return true
+}
@@ -0,0 +1,139 @@
+package game
+
+import "math/rand"
+
+// LootEntry — одна строка таблицы дропа.
+// Пустой Petal означает результат "ничего не выпало".
+type LootEntry struct {
+	Petal  PetalType
+	Weight float64
+}
+
+// LootTable — таблица дропа для конкретного типа и редкости моба
+type LootTable struct {
+	Rolls         int     // сколько раз бросаем таблицу за одно убийство
+	DropChance    float64 // шанс, что бросок вообще что-то даст (0..1)
+	UpgradeChance float64 // шанс поднять редкость дропа на одну ступень
+	Entries       []LootEntry
+}
+
+// LootDrop — результат броска таблицы
+type LootDrop struct {
+	Type   PetalType `json:"type"`
+	Rarity Rarity    `json:"rarity"`
+}
+
+// LootTables — таблицы дропа по типу моба и редкости.
+// Если для редкости нет своей таблицы, используется таблица RarityCommon.
+var LootTables = map[MobType]map[Rarity]LootTable{
+	MobTypeGoblin: {
+		RarityCommon: {
+			Rolls: 1, DropChance: 0.9, UpgradeChance: 0.02,
+			Entries: []LootEntry{{PetalTypeGoblin, 80}, {"", 20}},
+		},
+		RarityRare: {
+			Rolls: 2, DropChance: 0.9, UpgradeChance: 0.05,
+			Entries: []LootEntry{{PetalTypeGoblin, 70}, {PetalTypeOrc, 10}, {PetalTypePollen, 5}, {"", 15}},
+		},
+		RarityLegendary: {
+			Rolls: 3, DropChance: 1.0, UpgradeChance: 0,
+			Entries: []LootEntry{{PetalTypeGoblin, 60}, {PetalTypeOrc, 15}, {PetalTypeWolf, 15}, {PetalTypePollen, 10}},
+		},
+	},
+	MobTypeOrc: {
+		RarityCommon: {
+			Rolls: 1, DropChance: 0.85, UpgradeChance: 0.03,
+			Entries: []LootEntry{{PetalTypeOrc, 80}, {PetalTypeSpear, 5}, {"", 15}},
+		},
+		RarityEpic: {
+			Rolls: 2, DropChance: 0.95, UpgradeChance: 0.08,
+			Entries: []LootEntry{{PetalTypeOrc, 70}, {PetalTypeGoblin, 10}, {PetalTypeSpear, 10}, {"", 10}},
+		},
+		RarityLegendary: {
+			Rolls: 3, DropChance: 1.0, UpgradeChance: 0,
+			Entries: []LootEntry{{PetalTypeOrc, 55}, {PetalTypeGoblin, 15}, {PetalTypeWolf, 15}, {PetalTypeSpear, 15}},
+		},
+	},
+	MobTypeOgreKing: {
+		RarityCommon: {
+			Rolls: 2, DropChance: 1.0, UpgradeChance: 0.1,
+			Entries: []LootEntry{{PetalTypeOrc, 30}, {PetalTypeSpear, 25}, {PetalTypeShell, 20}, {PetalTypeEgg, 15}, {PetalTypePollen, 10}},
+		},
+	},
+	MobTypeWolf: {
+		RarityCommon: {
+			Rolls: 1, DropChance: 0.8, UpgradeChance: 0.02,
+			Entries: []LootEntry{{PetalTypeWolf, 82}, {PetalTypeShell, 4}, {PetalTypeEgg, 4}, {"", 10}},
+		},
+		RarityLegendary: {
+			Rolls: 3, DropChance: 1.0, UpgradeChance: 0,
+			Entries: []LootEntry{{PetalTypeWolf, 60}, {PetalTypeGoblin, 10}, {PetalTypeOrc, 10}, {PetalTypeShell, 10}, {PetalTypeEgg, 10}},
+		},
+	},
+}
+
+// lootTableFor — ищет таблицу для типа и редкости (с откатом на common)
+func lootTableFor(mobType MobType, rarity Rarity) (LootTable, bool) {
+	byRarity, ok := LootTables[mobType]
+	if !ok {
+		return LootTable{}, false
+	}
+	if table, ok := byRarity[rarity]; ok {
+		return table, true
+	}
+	table, ok := byRarity[RarityCommon]
+	return table, ok
+}
+
+// RollLoot — бросает таблицу дропа моба. Дроп получает редкость моба,
+// которая может быть повышена по UpgradeChance.
+func RollLoot(rng *rand.Rand, mobType MobType, rarity Rarity) []LootDrop {
+	table, ok := lootTableFor(mobType, rarity)
+	if !ok {
+		return nil
+	}
+	return table.Roll(rng, rarity)
+}
+
+// Roll — бросает таблицу Rolls раз
+func (t LootTable) Roll(rng *rand.Rand, rarity Rarity) []LootDrop {
+	drops := make([]LootDrop, 0, t.Rolls)
+	for i := 0; i < t.Rolls; i++ {
+		if rng.Float64() >= t.DropChance {
+			continue
+		}
+
+		petalType := t.pick(rng)
+		if petalType == "" {
+			continue // выпало "ничего"
+		}
+
+		dropRarity := rarity
+		if t.UpgradeChance > 0 && rng.Float64() < t.UpgradeChance {
+			dropRarity = rarity.Next()
+		}
+
+		drops = append(drops, LootDrop{Type: petalType, Rarity: dropRarity})
+	}
+	return drops
+}
+
+// pick — взвешенный выбор записи таблицы
+func (t LootTable) pick(rng *rand.Rand) PetalType {
+	total := 0.0
+	for _, e := range t.Entries {
+		total += e.Weight
+	}
+	if total <= 0 {
+		return ""
+	}
+
+	r := rng.Float64() * total
+	for _, e := range t.Entries {
+		r -= e.Weight
+		if r < 0 {
+			return e.Petal
+		}
+	}
+	return t.Entries[len(t.Entries)-1].Petal
+}
@@ -0,0 +1,160 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+const lootTestRolls = 200000
+
+// lootStats — частоты исходов одного броска таблицы
+type lootStats struct {
+	drops    int
+	petals   map[PetalType]int
+	upgrades int
+}
+
+func rollLootStats(t LootTable, seed int64, rarity Rarity) lootStats {
+	rng := rand.New(rand.NewSource(seed))
+	stats := lootStats{petals: make(map[PetalType]int)}
+	for i := 0; i < lootTestRolls; i++ {
+		for _, drop := range t.Roll(rng, rarity) {
+			stats.drops++
+			stats.petals[drop.Type]++
+			if drop.Rarity != rarity {
+				stats.upgrades++
+			}
+		}
+	}
+	return stats
+}
+
+func assertRate(t *testing.T, what string, got, want float64) {
+	t.Helper()
+	const tolerance = 0.01
+	if math.Abs(got-want) > tolerance {
+		t.Errorf("%s: rate %.4f, want %.4f ± %.2f", what, got, want, tolerance)
+	}
+}
+
+func TestLootTableRoll(t *testing.T) {
+	tests := []struct {
+		name  string
+		table LootTable
+		// ожидаемая доля бросков, давших лепесток данного типа
+		want        map[PetalType]float64
+		wantUpgrade float64 // доля повышенных дропов среди выпавших
+	}{
+		{
+			name: "weights",
+			table: LootTable{Rolls: 1, DropChance: 1, Entries: []LootEntry{
+				{PetalTypeGoblin, 60}, {PetalTypeOrc, 30}, {PetalTypeWolf, 10},
+			}},
+			want: map[PetalType]float64{PetalTypeGoblin: 0.6, PetalTypeOrc: 0.3, PetalTypeWolf: 0.1},
+		},
+		{
+			name: "drop chance",
+			table: LootTable{Rolls: 1, DropChance: 0.25, Entries: []LootEntry{
+				{PetalTypeGoblin, 1},
+			}},
+			want: map[PetalType]float64{PetalTypeGoblin: 0.25},
+		},
+		{
+			name: "nothing entry",
+			table: LootTable{Rolls: 1, DropChance: 1, Entries: []LootEntry{
+				{PetalTypeOrc, 70}, {"", 30},
+			}},
+			want: map[PetalType]float64{PetalTypeOrc: 0.7},
+		},
+		{
+			name: "drop chance and nothing",
+			table: LootTable{Rolls: 2, DropChance: 0.5, Entries: []LootEntry{
+				{PetalTypeWolf, 50}, {"", 50},
+			}},
+			want: map[PetalType]float64{PetalTypeWolf: 0.5 * 0.5},
+		},
+		{
+			name: "upgrade rate",
+			table: LootTable{Rolls: 1, DropChance: 1, UpgradeChance: 0.1, Entries: []LootEntry{
+				{PetalTypeGoblin, 1},
+			}},
+			want:        map[PetalType]float64{PetalTypeGoblin: 1},
+			wantUpgrade: 0.1,
+		},
+		{
+			name:  "empty table",
+			table: LootTable{Rolls: 3, DropChance: 1},
+			want:  map[PetalType]float64{},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := rollLootStats(tt.table, int64(i+1), RarityCommon)
+			rolls := float64(lootTestRolls * max(tt.table.Rolls, 1))
+			for petal, want := range tt.want {
+				assertRate(t, string(petal), float64(stats.petals[petal])/rolls, want)
+			}
+			for petal := range stats.petals {
+				if _, ok := tt.want[petal]; !ok {
+					t.Errorf("unexpected petal %q", petal)
+				}
+			}
+			if stats.drops > 0 {
+				assertRate(t, "upgrade", float64(stats.upgrades)/float64(stats.drops), tt.wantUpgrade)
+			}
+		})
+	}
+}
+
+func TestLootTableRollUpgradeCap(t *testing.T) {
+	table := LootTable{Rolls: 1, DropChance: 1, UpgradeChance: 1, Entries: []LootEntry{{PetalTypeGoblin, 1}}}
+	rng := rand.New(rand.NewSource(1))
+	for _, rarity := range rarityOrder {
+		drops := table.Roll(rng, rarity)
+		if len(drops) != 1 || drops[0].Rarity != rarity.Next() {
+			t.Errorf("%s: got %v, want one drop of %s", rarity, drops, rarity.Next())
+		}
+	}
+}
+
+func TestRollLootSeeded(t *testing.T) {
+	tests := []struct {
+		mobType MobType
+		rarity  Rarity
+	}{
+		{MobTypeGoblin, RarityCommon},
+		{MobTypeGoblin, RarityUncommon}, // откат на таблицу common
+		{MobTypeOrc, RarityEpic},
+		{MobTypeWolf, RarityLegendary},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mobType)+"/"+string(tt.rarity), func(t *testing.T) {
+			a := RollLoot(rand.New(rand.NewSource(42)), tt.mobType, tt.rarity)
+			b := RollLoot(rand.New(rand.NewSource(42)), tt.mobType, tt.rarity)
+			if len(a) != len(b) {
+				t.Fatalf("same seed gave %v and %v", a, b)
+			}
+			for i := range a {
+				if a[i] != b[i] {
+					t.Fatalf("same seed gave %v and %v", a, b)
+				}
+			}
+
+			table, _ := lootTableFor(tt.mobType, tt.rarity)
+			if len(a) > table.Rolls {
+				t.Errorf("%d drops from %d rolls", len(a), table.Rolls)
+			}
+			for _, drop := range a {
+				if drop.Rarity != tt.rarity && drop.Rarity != tt.rarity.Next() {
+					t.Errorf("drop rarity %s from %s mob", drop.Rarity, tt.rarity)
+				}
+			}
+		})
+	}
+
+	if drops := RollLoot(rand.New(rand.NewSource(1)), MobType("unknown"), RarityCommon); drops != nil {
+		t.Errorf("unknown mob type dropped %v", drops)
+	}
+}
@@ -0,0 +1,422 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Параметры генератора
+const (
+	GenZoneGap       = 1000.0 // промежуток между зонами, как на обычных картах
+	GenPortalInset   = 250.0  // отступ портала от края зоны
+	GenEdgeMargin    = 150.0  // препятствия не ставятся вплотную к краю
+	GenMaxAttempts   = 20     // попыток разложить препятствия так, чтобы порталы были связаны
+	GenRegionRetries = 30     // попыток поставить одну область спавна
+	GenMapPrefix     = "gen:" // имя карты вида gen:<шаблон>:<seed>[:<зон>]
+)
+
+// GenRange — отрезок для случайного выбора
+type GenRange struct {
+	Min, Max float64
+}
+
+func (r GenRange) roll(rng *rand.Rand) float64 {
+	return r.Min + rng.Float64()*(r.Max-r.Min)
+}
+
+func (r GenRange) rollInt(rng *rand.Rand) int {
+	return int(math.Round(r.roll(rng)))
+}
+
+// GenTemplate — шаблон процедурной зоны
+type GenTemplate struct {
+	ZoneWidth, ZoneHeight float64
+	Colors                []string // цвет зоны по порядку (последний повторяется)
+	MobTypes              []MobType
+
+	Clusters       GenRange       // число скоплений препятствий
+	ClusterSize    GenRange       // препятствий в скоплении
+	ClusterSpread  float64        // разброс препятствий вокруг центра скопления
+	ObstacleKinds  []ObstacleKind // из чего состоят скопления
+	ObstacleRadius GenRange       // радиус камней и озёр
+	WallLength     GenRange       // длина стен
+	WallThickness  float64
+
+	Clearings      int     // случайных полян без препятствий (кроме полян у порталов)
+	ClearingRadius float64 // радиус поляны
+	SpawnRegions   GenRange
+	RegionSize     GenRange // сторона прямоугольной области спавна
+}
+
+// GenTemplates — шаблоны генератора по имени
+var GenTemplates = map[string]GenTemplate{
+	"grassland": {
+		ZoneWidth: 6000, ZoneHeight: 3000,
+		Colors:         []string{"#A8D08D", "#8FBF6A", "#6FA84F", "#4F8A35", "#3A6B26"},
+		MobTypes:       []MobType{MobTypeGoblin, MobTypeWolf, MobTypeOrc},
+		Clusters:       GenRange{6, 10},
+		ClusterSize:    GenRange{2, 4},
+		ClusterSpread:  250,
+		ObstacleKinds:  []ObstacleKind{ObstacleRock, ObstacleRock, ObstacleWater},
+		ObstacleRadius: GenRange{60, 180},
+		WallLength:     GenRange{300, 700},
+		WallThickness:  80,
+		Clearings:      3,
+		ClearingRadius: 300,
+		SpawnRegions:   GenRange{3, 5},
+		RegionSize:     GenRange{800, 1400},
+	},
+	"ruins": {
+		ZoneWidth: 5000, ZoneHeight: 3000,
+		Colors:         []string{"#9E9E9E", "#8E8E8E", "#7A7A7A", "#666666", "#524A5E"},
+		MobTypes:       []MobType{MobTypeOrc, MobTypeGoblin},
+		Clusters:       GenRange{8, 12},
+		ClusterSize:    GenRange{1, 3},
+		ClusterSpread:  300,
+		ObstacleKinds:  []ObstacleKind{ObstacleWall, ObstacleWall, ObstacleRock},
+		ObstacleRadius: GenRange{50, 120},
+		WallLength:     GenRange{400, 1000},
+		WallThickness:  100,
+		Clearings:      2,
+		ClearingRadius: 350,
+		SpawnRegions:   GenRange{2, 4},
+		RegionSize:     GenRange{700, 1200},
+	},
+	"swamp": {
+		ZoneWidth: 6000, ZoneHeight: 3500,
+		Colors:         []string{"#6B8E5A", "#5A7D4A", "#4A6B3C", "#3B5A30", "#2E4A26"},
+		MobTypes:       []MobType{MobTypeWolf, MobTypeGoblin},
+		Clusters:       GenRange{8, 14},
+		ClusterSize:    GenRange{2, 5},
+		ClusterSpread:  350,
+		ObstacleKinds:  []ObstacleKind{ObstacleWater, ObstacleWater, ObstacleRock},
+		ObstacleRadius: GenRange{100, 260},
+		WallLength:     GenRange{300, 600},
+		WallThickness:  80,
+		Clearings:      4,
+		ClearingRadius: 280,
+		SpawnRegions:   GenRange{3, 6},
+		RegionSize:     GenRange{700, 1300},
+	},
+}
+
+// GenInfo — откуда взялась сгенерированная карта (её можно пересоздать)
+type GenInfo struct {
+	Template string `json:"template"`
+	Seed     int64  `json:"seed"`
+	Zones    int    `json:"zones"`
+}
+
+// genClearing — круг, в который не ставятся препятствия
+type genClearing struct {
+	X, Y, Radius float64
+}
+
+// GenerateMap строит карту из zones зон по шаблону. Одинаковые шаблон,
+// seed и число зон всегда дают одну и ту же карту.
+func GenerateMap(templateName string, seed int64, zones int) (*MapDef, error) {
+	template, ok := GenTemplates[templateName]
+	if !ok {
+		return nil, fmt.Errorf("mapgen: unknown template %q", templateName)
+	}
+	if zones < 1 {
+		return nil, fmt.Errorf("mapgen: need at least one zone, got %d", zones)
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	m := &MapDef{
+		Name:      fmt.Sprintf("gen_%s_%d", templateName, seed),
+		Width:     float64(zones)*template.ZoneWidth + float64(zones-1)*GenZoneGap,
+		Height:    template.ZoneHeight,
+		Generated: &GenInfo{Template: templateName, Seed: seed, Zones: zones},
+	}
+
+	for i := 0; i < zones; i++ {
+		minX := float64(i) * (template.ZoneWidth + GenZoneGap)
+		zone := &Zone{
+			Name:     fmt.Sprintf("zone_%d", i+1),
+			MinX:     minX,
+			MaxX:     minX + template.ZoneWidth,
+			MinY:     0,
+			MaxY:     template.ZoneHeight,
+			Color:    template.Colors[min(i, len(template.Colors)-1)],
+			MobTypes: template.MobTypes,
+		}
+		// Чем дальше зона, тем реже мобы (как common → legendary на обычной карте)
+		tier := rarityOrder[min(i, len(rarityOrder)-1)]
+		zone.RarityDistribution = ZoneRarityDistribution[string(tier)]
+		if settings, ok := ZoneAffixSettings[string(tier)]; ok {
+			zone.Affixes = &settings
+		}
+		if i == 0 {
+			m.SpawnZone = zone.Name
+		}
+
+		// Порталы: вход у левого края, выход у правого
+		midY := template.ZoneHeight / 2
+		var clearings []genClearing
+		var portalPoints []Point
+		if i > 0 {
+			p := &Portal{
+				ID: fmt.Sprintf("%s_in", zone.Name), Zone: zone.Name,
+				X: zone.MinX + GenPortalInset, Y: math.Round(midY + (rng.Float64()*2-1)*midY/2),
+				To: fmt.Sprintf("zone_%d_out", i),
+			}
+			m.Portals = append(m.Portals, p)
+			portalPoints = append(portalPoints, Point{p.X, p.Y})
+		} else {
+			// Точка появления игроков тоже должна быть связана с выходом;
+			// вокруг неё — безопасный лагерь
+			portalPoints = append(portalPoints, Point{zone.MinX + GenPortalInset, midY})
+			m.Regions = append(m.Regions, &Region{
+				ID: zone.Name + "_camp", Zone: zone.Name, Kind: RegionSafe, Name: "Camp",
+				Shape: RegionCircle, X: zone.MinX + GenPortalInset, Y: midY, Radius: template.ClearingRadius,
+			})
+		}
+		if i < zones-1 {
+			p := &Portal{
+				ID: fmt.Sprintf("%s_out", zone.Name), Zone: zone.Name,
+				X: zone.MaxX - GenPortalInset, Y: math.Round(midY + (rng.Float64()*2-1)*midY/2),
+				To: fmt.Sprintf("zone_%d_in", i+2),
+			}
+			m.Portals = append(m.Portals, p)
+			portalPoints = append(portalPoints, Point{p.X, p.Y})
+		}
+		for _, p := range portalPoints {
+			clearings = append(clearings, genClearing{p.X, p.Y, template.ClearingRadius})
+		}
+		for c := 0; c < template.Clearings; c++ {
+			x, y := genPointIn(rng, zone, template.ClearingRadius)
+			clearings = append(clearings, genClearing{x, y, template.ClearingRadius})
+			m.POIs = append(m.POIs, &POI{
+				ID: fmt.Sprintf("%s_clearing_%d", zone.Name, c+1), Zone: zone.Name,
+				Kind: "clearing", Name: "Clearing", X: x, Y: y,
+			})
+		}
+
+		// Раскладываем препятствия, пока все порталы зоны не окажутся связаны
+		placed := false
+		for attempt := 0; attempt < GenMaxAttempts; attempt++ {
+			zone.Obstacles = genObstacles(rng, template, zone, clearings)
+			if genConnected(zone, portalPoints) {
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			return nil, fmt.Errorf("mapgen: zone %q: portals are not connected after %d attempts", zone.Name, GenMaxAttempts)
+		}
+
+		m.SpawnRegions = append(m.SpawnRegions, genSpawnRegions(rng, template, zone, clearings)...)
+		m.Zones = append(m.Zones, zone)
+	}
+
+	// Через файловый формат: проверка валидатором и раскладка областей по зонам.
+	// Не через ExportMap — блок generated здесь нужен, чтобы карту можно было пересоздать.
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("mapgen: %w", err)
+	}
+	return ParseMap(data)
+}
+
+// genPointIn — случайная точка в зоне с отступом от краёв
+func genPointIn(rng *rand.Rand, zone *Zone, margin float64) (float64, float64) {
+	margin = math.Max(margin, GenEdgeMargin)
+	x := zone.MinX + margin + rng.Float64()*(zone.MaxX-zone.MinX-2*margin)
+	y := zone.MinY + margin + rng.Float64()*(zone.MaxY-zone.MinY-2*margin)
+	return math.Round(x), math.Round(y)
+}
+
+// genObstacles — скопления препятствий вне полян
+func genObstacles(rng *rand.Rand, template GenTemplate, zone *Zone, clearings []genClearing) []*Obstacle {
+	var obstacles []*Obstacle
+	clusters := template.Clusters.rollInt(rng)
+	for c := 0; c < clusters; c++ {
+		cx, cy := genPointIn(rng, zone, template.ClusterSpread)
+		size := template.ClusterSize.rollInt(rng)
+		for n := 0; n < size; n++ {
+			x := cx + (rng.Float64()*2-1)*template.ClusterSpread
+			y := cy + (rng.Float64()*2-1)*template.ClusterSpread
+			kind := template.ObstacleKinds[rng.Intn(len(template.ObstacleKinds))]
+			id := fmt.Sprintf("%s_%s_%d", zone.Name, kind, len(obstacles)+1)
+
+			var o *Obstacle
+			if kind == ObstacleWall {
+				o = genWall(rng, template, id, x, y)
+			} else {
+				o = &Obstacle{ID: id, Kind: kind, Shape: ObstacleCircle, X: x, Y: y, Radius: template.ObstacleRadius.roll(rng)}
+			}
+			if genObstacleFits(o, zone, clearings) {
+				obstacles = append(obstacles, o)
+			}
+		}
+	}
+	return obstacles
+}
+
+// genWall — прямоугольная стена со случайным поворотом
+func genWall(rng *rand.Rand, template GenTemplate, id string, x, y float64) *Obstacle {
+	length := template.WallLength.roll(rng)
+	angle := rng.Float64() * math.Pi
+	ux, uy := math.Cos(angle)*length/2, math.Sin(angle)*length/2
+	vx, vy := -math.Sin(angle)*template.WallThickness/2, math.Cos(angle)*template.WallThickness/2
+	round := func(v float64) float64 { return math.Round(v) }
+	return &Obstacle{
+		ID: id, Kind: ObstacleWall, Shape: ObstaclePolygon,
+		Points: []Point{
+			{round(x - ux - vx), round(y - uy - vy)},
+			{round(x + ux - vx), round(y + uy - vy)},
+			{round(x + ux + vx), round(y + uy + vy)},
+			{round(x - ux + vx), round(y - uy + vy)},
+		},
+	}
+}
+
+// genObstacleFits — препятствие внутри зоны и не задевает поляны
+func genObstacleFits(o *Obstacle, zone *Zone, clearings []genClearing) bool {
+	points := o.Points
+	if o.Shape == ObstacleCircle {
+		o.X, o.Y, o.Radius = math.Round(o.X), math.Round(o.Y), math.Round(o.Radius)
+		points = []Point{{o.X - o.Radius, o.Y - o.Radius}, {o.X + o.Radius, o.Y + o.Radius}}
+	}
+	for _, p := range points {
+		if p.X < zone.MinX+GenEdgeMargin || p.X > zone.MaxX-GenEdgeMargin ||
+			p.Y < zone.MinY+GenEdgeMargin || p.Y > zone.MaxY-GenEdgeMargin {
+			return false
+		}
+	}
+	for _, c := range clearings {
+		if o.Overlaps(c.X, c.Y, c.Radius) {
+			return false
+		}
+	}
+	return true
+}
+
+// genConnected — можно ли дойти от первой точки до всех остальных
+func genConnected(zone *Zone, points []Point) bool {
+	if len(points) < 2 {
+		return true
+	}
+	grid := buildNavGrid(zone)
+	start := grid.cellAt(points[0].X, points[0].Y)
+	if grid.blocked(start) {
+		return false
+	}
+
+	// Заливка от первой точки по 4 направлениям
+	visited := make([]bool, len(grid.Blocked))
+	visited[start.Y*grid.Cols+start.X] = true
+	queue := []navCell{start}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		for _, d := range []navCell{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			next := navCell{c.X + d.X, c.Y + d.Y}
+			if grid.blocked(next) || visited[next.Y*grid.Cols+next.X] {
+				continue
+			}
+			visited[next.Y*grid.Cols+next.X] = true
+			queue = append(queue, next)
+		}
+	}
+
+	for _, p := range points[1:] {
+		c := grid.cellAt(p.X, p.Y)
+		if !visited[c.Y*grid.Cols+c.X] {
+			return false
+		}
+	}
+	return true
+}
+
+// genSpawnRegions — прямоугольные области спавна, не задевающие поляны
+func genSpawnRegions(rng *rand.Rand, template GenTemplate, zone *Zone, clearings []genClearing) []*SpawnRegion {
+	var regions []*SpawnRegion
+	count := template.SpawnRegions.rollInt(rng)
+	for r := 0; r < count; r++ {
+		for try := 0; try < GenRegionRetries; try++ {
+			w := math.Round(template.RegionSize.roll(rng))
+			h := math.Round(math.Min(template.RegionSize.roll(rng), zone.MaxY-zone.MinY-2*GenEdgeMargin))
+			x := math.Round(zone.MinX + GenEdgeMargin + rng.Float64()*(zone.MaxX-zone.MinX-2*GenEdgeMargin-w))
+			y := math.Round(zone.MinY + GenEdgeMargin + rng.Float64()*(zone.MaxY-zone.MinY-2*GenEdgeMargin-h))
+			region := &SpawnRegion{
+				ID: fmt.Sprintf("%s_region_%d", zone.Name, len(regions)+1), Zone: zone.Name,
+				MinX: x, MaxX: x + w, MinY: y, MaxY: y + h,
+				Weight: float64(1 + rng.Intn(3)),
+			}
+			if !genRegionHitsClearing(region, clearings) {
+				regions = append(regions, region)
+				break
+			}
+		}
+	}
+	return regions
+}
+
+func genRegionHitsClearing(r *SpawnRegion, clearings []genClearing) bool {
+	for _, c := range clearings {
+		dx := math.Max(0, math.Max(r.MinX-c.X, c.X-r.MaxX))
+		dy := math.Max(0, math.Max(r.MinY-c.Y, c.Y-r.MaxY))
+		if dx*dx+dy*dy < c.Radius*c.Radius {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportMap — карта в формате content/maps для ручной правки. Блок generated
+// не выгружается: правленая карта не должна пересоздаваться из seed.
+func ExportMap(m *MapDef) ([]byte, error) {
+	export := *m
+	export.Generated = nil
+	data, err := json.MarshalIndent(&export, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("export map %q: %w", m.Name, err)
+	}
+	return append(data, '\n'), nil
+}
+
+// GenTemplateNames — имена шаблонов генератора по алфавиту
+func GenTemplateNames() []string {
+	names := make([]string, 0, len(GenTemplates))
+	for name := range GenTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveMap — карта по имени: из content/maps или сгенерированная
+// по строке вида gen:<шаблон>:<seed>[:<зон>]
+func ResolveMap(name string) (*MapDef, error) {
+	if !strings.HasPrefix(name, GenMapPrefix) {
+		m, ok := lookupMap(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown map %q, available: %v", name, MapNames())
+		}
+		return m, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(name, GenMapPrefix), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("generated map %q: want gen:<template>:<seed>[:<zones>]", name)
+	}
+	seed, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("generated map %q: bad seed: %w", name, err)
+	}
+	zones := 3
+	if len(parts) == 3 {
+		if zones, err = strconv.Atoi(parts[2]); err != nil {
+			return nil, fmt.Errorf("generated map %q: bad zone count: %w", name, err)
+		}
+	}
+	return GenerateMap(parts[0], seed, zones)
+}
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// mapgenTestSeeds — несколько seed для проверки каждого шаблона
+var mapgenTestSeeds = []int64{1, 7, 42, 1234}
+
+func TestGenerateMapDeterministic(t *testing.T) {
+	for _, template := range GenTemplateNames() {
+		for _, seed := range mapgenTestSeeds {
+			t.Run(fmt.Sprintf("%s/%d", template, seed), func(t *testing.T) {
+				a := exportGenerated(t, template, seed, 3)
+				b := exportGenerated(t, template, seed, 3)
+				if !bytes.Equal(a, b) {
+					t.Errorf("two runs with the same seed exported different maps")
+				}
+			})
+		}
+	}
+}
+
+func TestGenerateMapZonesConnected(t *testing.T) {
+	for _, template := range GenTemplateNames() {
+		for _, seed := range mapgenTestSeeds {
+			t.Run(fmt.Sprintf("%s/%d", template, seed), func(t *testing.T) {
+				m, err := GenerateMap(template, seed, 4)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, zone := range m.Zones {
+					var points []Point
+					for _, p := range m.Portals {
+						if p.Zone == zone.Name {
+							points = append(points, Point{p.X, p.Y})
+						}
+					}
+					if len(points) == 0 {
+						t.Errorf("zone %q has no portals", zone.Name)
+					}
+					if !genConnected(zone, points) {
+						t.Errorf("zone %q: portals are not connected", zone.Name)
+					}
+				}
+			})
+		}
+	}
+}
+
+func exportGenerated(t *testing.T, template string, seed int64, zones int) []byte {
+	t.Helper()
+	m, err := GenerateMap(template, seed, zones)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ExportMap(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
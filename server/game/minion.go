@@ -0,0 +1,85 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Minion — временный союзник, призванный лепестком
+type Minion struct {
+	ID         string    `json:"id"`
+	OwnerID    string    `json:"owner_id"`
+	Zone       string    `json:"zone"`
+	X          float64   `json:"x"`
+	Y          float64   `json:"y"`
+	Radius     float64   `json:"radius"`
+	Speed      float64   `json:"-"`
+	Damage     int       `json:"-"`
+	Expires    time.Time `json:"-"`
+	LastAttack time.Time `json:"-"`
+}
+
+const (
+	MinionRadius      = 10.0
+	MinionSpeed       = 4.0
+	MinionAggroRange  = 400.0 // как далеко от владельца союзник ищет цель
+	MaxMinionsPerUser = 3
+)
+
+// spawnMinion — призывает союзника рядом с лепестком (вызывается под g.mu)
+func (g *Game) spawnMinion(player *Player, x, y float64, damage int, lifetime time.Duration) {
+	count := 0
+	for _, m := range g.minions {
+		if m.OwnerID == player.ID {
+			count++
+		}
+	}
+	if count >= MaxMinionsPerUser {
+		return
+	}
+
+	g.entitySeq++
+	minion := &Minion{
+		ID:      fmt.Sprintf("minion_%d_%d", time.Now().UnixNano(), g.entitySeq),
+		OwnerID: player.ID,
+		Zone:    player.CurrentZone,
+		X:       x,
+		Y:       y,
+		Radius:  MinionRadius,
+		Speed:   MinionSpeed,
+		Damage:  damage,
+		Expires: time.Now().Add(lifetime),
+	}
+	g.minions[minion.ID] = minion
+}
+
+// updateMinionsLocked — союзники преследуют ближайшего к владельцу моба
+func (g *Game) updateMinionsLocked(now time.Time) {
+	for id, minion := range g.minions {
+		owner := g.players[minion.OwnerID]
+		if owner == nil || !owner.IsAlive() || owner.CurrentZone != minion.Zone || now.After(minion.Expires) {
+			delete(g.minions, id)
+			continue
+		}
+
+		targetX, targetY := owner.X, owner.Y
+		mob, distance := g.findClosestMobInZoneLocked(owner.X, owner.Y, owner.CurrentZone)
+		if mob != nil && distance <= MinionAggroRange {
+			targetX, targetY = mob.X, mob.Y
+		}
+
+		dx := targetX - minion.X
+		dy := targetY - minion.Y
+		if dist := math.Sqrt(dx*dx + dy*dy); dist > minion.Speed {
+			minion.X += dx / dist * minion.Speed
+			minion.Y += dy / dist * minion.Speed
+		}
+
+		if mob != nil && mob.DistanceTo(minion.X, minion.Y) < mob.Radius+minion.Radius &&
+			now.Sub(minion.LastAttack) >= 500*time.Millisecond {
+			minion.LastAttack = now
+			g.damageMobByPlayer(owner, mob, minion.Damage)
+		}
+	}
+}
@@ -16,6 +16,19 @@ const (
 	RarityLegendary Rarity = "legendary"
 )
 
+// rarityOrder — редкости по возрастанию
+var rarityOrder = []Rarity{RarityCommon, RarityUncommon, RarityRare, RarityEpic, RarityLegendary}
+
+// Next возвращает следующую по старшинству редкость (legendary остаётся legendary)
+func (r Rarity) Next() Rarity {
+	for i, rr := range rarityOrder {
+		if rr == r && i+1 < len(rarityOrder) {
+			return rarityOrder[i+1]
+		}
+	}
+	return r
+}
+
 // Множители характеристик для редкостей
 var RarityMultipliers = map[Rarity]struct {
 	HealthMultiplier float64
@@ -65,6 +78,8 @@ const (
 	MobTypeGoblin MobType = "goblin"
 	MobTypeOrc    MobType = "orc"
 	MobTypeWolf   MobType = "wolf"
+
+	MobTypeOgreKing MobType = "ogre_king" // босс легендарной зоны
 )
 
 type MobState string
@@ -74,19 +89,93 @@ const (
 	MobStateChasing   MobState = "chasing"
 	MobStateAttacking MobState = "attacking"
 	MobStateFleeing   MobState = "fleeing"
+	MobStateReturning MobState = "returning" // возвращается к точке спавна
 )
 
-// Константы для разных типов мобов
-var MobConfigs = map[MobType]struct {
+// MobConfig — базовые характеристики и параметры поведения типа моба
+type MobConfig struct {
 	Health         int
 	Damage         int
 	Speed          float64
 	Radius         float64
 	DetectionRange float64
-}{
-	MobTypeGoblin: {Health: 30, Damage: 8, Speed: 10.0, Radius: 20.0, DetectionRange: 500},
-	MobTypeOrc:    {Health: 80, Damage: 15, Speed: 20.0, Radius: 25.0, DetectionRange: 500},
-	MobTypeWolf:   {Health: 40, Damage: 10, Speed: 15.0, Radius: 16.0, DetectionRange: 500},
+	OnHit          []EffectSpec // эффекты, которые моб накладывает при ударе по игроку или лепестку
+
+	// Поведение (см. RegisterMobBehavior)
+	Behavior        string
+	AI              string  // имя автомата из content/mob_ai.json (для Behavior == "fsm")
+	WanderSpeed     float64 // скорость при блуждании (0 — базовая Speed)
+	ChaseSpeed      float64 // скорость при преследовании
+	ZigzagAmplitude float64 // сила зигзага при преследовании (0 — по прямой)
+	RetargetDelay   float64 // секунд между пересчётами цели при преследовании
+	AttackCooldown  float64 // секунд между рывками в атаку
+	FleeDistance    float64 // как далеко моб отбегает при бегстве
+	WanderInterval  float64 // секунд между сменой точки блуждания
+	ProximityThreat float64 // угроза в секунду от игрока вплотную (0 — нейтральный моб)
+	// LeashRadius — радиус поводка от точки спавна по редкостям
+	// (если редкости нет в карте, берётся значение для RarityCommon)
+	LeashRadius map[Rarity]float64
+	// Unique — моб не появляется при обычном спавне (боссы)
+	Unique bool
+	// Abilities — имена способностей из MobAbilities в порядке приоритета
+	Abilities []string
+}
+
+// Константы для разных типов мобов
+var MobConfigs = map[MobType]MobConfig{
+	MobTypeGoblin: {Health: 30, Damage: 8, Speed: 10.0, Radius: 20.0, DetectionRange: 500,
+		Behavior:        MobBehaviorFSM,
+		AI:              "goblin",
+		Abilities:       []string{"petal_steal"},
+		ProximityThreat: 5,
+		LeashRadius:     map[Rarity]float64{RarityCommon: 600, RarityLegendary: 900},
+		FleeDistance:    200,
+		WanderInterval:  3,
+	},
+	MobTypeOrc: {Health: 80, Damage: 15, Speed: 20.0, Radius: 25.0, DetectionRange: 500,
+		OnHit: []EffectSpec{
+			{Type: EffectStun, Duration: 0.4, Magnitude: 1, Stacking: StackIgnore, Chance: 0.15},
+		},
+		Behavior:        MobBehaviorFSM,
+		AI:              "orc",
+		Abilities:       []string{"spear_throw"},
+		ProximityThreat: 10,
+		LeashRadius:     map[Rarity]float64{RarityCommon: 700, RarityRare: 850, RarityEpic: 1000, RarityLegendary: 1400},
+		WanderSpeed:     0.8,
+		ChaseSpeed:      18,
+		ZigzagAmplitude: 0.4,
+		RetargetDelay:   0.3,
+		AttackCooldown:  2,
+		WanderInterval:  3,
+	},
+	MobTypeWolf: {Health: 40, Damage: 10, Speed: 15.0, Radius: 16.0, DetectionRange: 500,
+		OnHit: []EffectSpec{
+			{Type: EffectPoison, Duration: 3, Magnitude: 2, Stacking: StackAdd, MaxStacks: 2, Chance: 0.5},
+		},
+		Behavior:       MobBehaviorFSM,
+		AI:             "wolf",
+		Abilities:      []string{"lunge"},
+		ChaseSpeed:     16,
+		RetargetDelay:  0.2,
+		AttackCooldown: 1.5,
+		LeashRadius:    map[Rarity]float64{RarityCommon: 500, RarityLegendary: 900},
+		WanderInterval: 3,
+	},
+	MobTypeOgreKing: {Health: 600, Damage: 12, Speed: 14.0, Radius: 28.0, DetectionRange: 700,
+		OnHit: []EffectSpec{
+			{Type: EffectSlow, Duration: 1.5, Magnitude: 0.35, Stacking: StackRefresh},
+		},
+		Behavior:        MobBehaviorAggressive,
+		WanderSpeed:     0.6,
+		ChaseSpeed:      9,
+		ZigzagAmplitude: 0.15,
+		RetargetDelay:   0.4,
+		AttackCooldown:  2.5,
+		WanderInterval:  4,
+		ProximityThreat: 15,
+		LeashRadius:     map[Rarity]float64{RarityCommon: 1200},
+		Unique:          true,
+	},
 }
 
 type Mob struct {
@@ -104,15 +193,47 @@ type Mob struct {
 	DetectionRange float64 `json:"-"`
 
 	// Поведение
-	TargetX        float64   `json:"-"`
-	TargetY        float64   `json:"-"`
-	LastMoveTime   time.Time `json:"-"`
-	State          MobState  `json:"-"`
-	TargetPlayer   string    `json:"-"`
-	AttackCooldown time.Time `json:"-"`
-	CreationTime   time.Time `json:"-"`
-	LastHitTime    time.Time `json:"-"`
-	LastAttackTime time.Time `json:"-"`
+	TargetX        float64            `json:"-"`
+	TargetY        float64            `json:"-"`
+	LastMoveTime   time.Time          `json:"-"`
+	State          MobState           `json:"-"`
+	TargetPlayer   string             `json:"-"`
+	AttackCooldown time.Time          `json:"-"`
+	CreationTime   time.Time          `json:"-"`
+	LastHitTime    time.Time          `json:"-"`
+	LastAttackTime time.Time          `json:"-"`
+	HelpCalledAt   time.Time          `json:"-"` // когда союзник позвал на помощь
+	Threat         map[string]float64 `json:"-"` // playerID → угроза
+	GroupID        string             `json:"group_id,omitempty"`
+	BossID         string             `json:"boss_id,omitempty"` // ID энкаунтера, если это босс
+
+	// Точка спавна и поводок (см. leash.go)
+	HomeX       float64 `json:"-"`
+	HomeY       float64 `json:"-"`
+	LeashRadius float64 `json:"-"`
+
+	// Маршрут в обход препятствий (см. navigation.go)
+	Path        []Point   `json:"-"`
+	PathIndex   int       `json:"-"`
+	PathGoal    Point     `json:"-"`
+	PathPlanned time.Time `json:"-"`
+
+	// Состояние автомата ИИ (см. mob_ai.go)
+	AIState      string    `json:"-"`
+	AIReason     string    `json:"-"`
+	AIStateSince time.Time `json:"-"`
+
+	// Способности (см. mob_ability.go)
+	Ability          *MobAbilityCast      `json:"ability,omitempty"`
+	AbilityCooldowns map[string]time.Time `json:"-"`
+	Stolen           *StolenPetal         `json:"stolen,omitempty"`
+
+	// Элитные аффиксы (см. affix.go)
+	Affixes     []Affix `json:"affixes,omitempty"`
+	regenCarry  float64 // дробный остаток регенерации между тиками
+	regionCarry float64 // дробный остаток урона опасных областей
+
+	Effects StatusEffects `json:"effects"`
 }
 
 func getRandomRarity(zone string) Rarity {
@@ -160,8 +281,14 @@ func applyRarityMultipliers(baseConfig MobType, rarity Rarity, zone string) (int
 
 func NewMob(id string, mobType MobType, x, y float64, zone string) *Mob {
 	// Определяем редкость для этой зоны
-	rarity := getRandomRarity(zone)
+	mob := newMobWithRarity(id, mobType, getRandomRarity(zone), x, y, zone)
+	// Элитные аффиксы по настройкам зоны (см. affix.go)
+	mob.applyAffixes(rollAffixes(zone))
+	return mob
+}
 
+// newMobWithRarity — создаёт моба с заданной редкостью (боссы, призванные мобы)
+func newMobWithRarity(id string, mobType MobType, rarity Rarity, x, y float64, zone string) *Mob {
 	// Применяем множители редкости к базовым характеристикам
 	health, damage, speed, radius := applyRarityMultipliers(mobType, rarity, zone)
 
@@ -181,8 +308,10 @@ func NewMob(id string, mobType MobType, x, y float64, zone string) *Mob {
 		LastMoveTime:   time.Now(),
 		LastAttackTime: time.Now(),
 		CreationTime:   time.Now(),
-		LastHitTime:    time.Now(),
 		State:          MobStateWandering,
+		HomeX:          x,
+		HomeY:          y,
+		LeashRadius:    leashRadiusFor(mobType, rarity),
 	}
 }
 
@@ -214,7 +343,7 @@ func (m *Mob) IsAlive() bool {
 
 // CanAttack проверяет, может ли моб атаковать (прошло ли 500мс с последней атаки)
 func (m *Mob) CanAttack() bool {
-	return time.Since(m.LastAttackTime) >= 500*time.Millisecond
+	return !m.Effects.IsStunned() && time.Since(m.LastAttackTime) >= 500*time.Millisecond
 }
 
 // MarkAttack отмечает момент атаки моба
@@ -224,7 +353,7 @@ func (m *Mob) MarkAttack() {
 
 // TakeDamage теперь НЕ влияет на возможность атаковать
 func (m *Mob) TakeDamage(damage int) {
-	m.Health -= damage
+	m.Health -= scaleDamage(damage, m.Effects.IncomingDamageMultiplier()*m.AffixDamageTakenMultiplier())
 	if m.Health < 0 {
 		m.Health = 0
 	}
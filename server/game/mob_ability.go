@@ -0,0 +1,367 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// AbilityPhase — фаза применения способности
+type AbilityPhase string
+
+const (
+	AbilityWindUp   AbilityPhase = "windup"   // замах: клиенты рисуют телеграф
+	AbilityActive   AbilityPhase = "active"   // действие
+	AbilityRecovery AbilityPhase = "recovery" // восстановление, моб уязвим
+)
+
+// Виды способностей (MobAbilities[...].Kind)
+const (
+	AbilityKindProjectile = "projectile" // бросок снаряда в точку прицеливания
+	AbilityKindLunge      = "lunge"      // рывок в точку прицеливания
+	AbilityKindStealPetal = "steal"      // крадёт лепесток и убегает
+)
+
+// MobAbility — описание способности моба
+type MobAbility struct {
+	Kind             string
+	MinRange         float64 // дистанция до цели, с которой способность применяется
+	MaxRange         float64
+	WindUp           float64 // секунды
+	Active           float64
+	Recovery         float64
+	Cooldown         float64 // секунд от конца замаха до следующего применения
+	DamageMultiplier float64 // доля урона моба
+	Speed            float64 // скорость снаряда или рывка (единиц за тик)
+	HoldTime         float64 // сколько секунд вор держит лепесток
+}
+
+// MobAbilities — способности по имени (MobConfigs[...].Abilities)
+var MobAbilities = map[string]MobAbility{
+	"spear_throw": {
+		Kind: AbilityKindProjectile, MinRange: 120, MaxRange: 450,
+		WindUp: 0.8, Recovery: 0.6, Cooldown: 5,
+		DamageMultiplier: 1.2, Speed: 30,
+	},
+	"lunge": {
+		Kind: AbilityKindLunge, MinRange: 60, MaxRange: 250,
+		WindUp: 0.5, Active: 0.35, Recovery: 0.5, Cooldown: 4,
+		DamageMultiplier: 1.5, Speed: 45,
+	},
+	"petal_steal": {
+		Kind: AbilityKindStealPetal, MaxRange: 70,
+		WindUp: 0.4, Recovery: 3, Cooldown: 12,
+		HoldTime: 10,
+	},
+}
+
+// MobAbilityCast — способность, которую моб применяет прямо сейчас
+type MobAbilityCast struct {
+	Name      string       `json:"name"`
+	Phase     AbilityPhase `json:"phase"`
+	TargetID  string       `json:"target_id"`
+	X         float64      `json:"x"` // точка прицеливания
+	Y         float64      `json:"y"`
+	PhaseEnds time.Time    `json:"-"`
+	Hit       bool         `json:"-"` // рывок уже задел цель
+}
+
+// StolenPetal — лепесток, который держит моб-вор
+type StolenPetal struct {
+	OwnerID  string    `json:"owner_id"`
+	PetalID  string    `json:"petal_id"`
+	Type     PetalType `json:"type"`
+	Releases time.Time `json:"-"`
+}
+
+// MobAbilityHandler — реализация вида способности. Методы вызываются под g.mu:
+// Activate — один раз в конце замаха, Active и Recover — каждый тик своей фазы.
+type MobAbilityHandler interface {
+	Activate(g *Game, mob *Mob, ability MobAbility, cast *MobAbilityCast, now time.Time)
+	Active(g *Game, mob *Mob, ability MobAbility, cast *MobAbilityCast, now time.Time)
+	Recover(g *Game, mob *Mob, ability MobAbility, cast *MobAbilityCast, now time.Time)
+}
+
+var mobAbilityHandlers = map[string]MobAbilityHandler{}
+
+// RegisterMobAbility регистрирует обработчик вида способности
+func RegisterMobAbility(kind string, handler MobAbilityHandler) {
+	mobAbilityHandlers[kind] = handler
+}
+
+func init() {
+	RegisterMobAbility(AbilityKindProjectile, projectileAbility{})
+	RegisterMobAbility(AbilityKindLunge, lungeAbility{})
+	RegisterMobAbility(AbilityKindStealPetal, stealAbility{})
+}
+
+// updateMobAbilityLocked — ведёт текущую способность или начинает новую.
+// Возвращает true, пока моб занят способностью (обычное поведение не выполняется).
+func (g *Game) updateMobAbilityLocked(mob *Mob, config MobConfig, target *Player, distance float64, now time.Time) bool {
+	g.releaseStolenPetalLocked(mob, now, false)
+
+	if mob.Ability == nil {
+		return g.startMobAbilityLocked(mob, config, target, distance, now)
+	}
+
+	cast := mob.Ability
+	ability := MobAbilities[cast.Name]
+	handler, ok := mobAbilityHandlers[ability.Kind]
+	if !ok {
+		mob.Ability = nil
+		return false
+	}
+
+	// Оглушение сбивает замах
+	if cast.Phase == AbilityWindUp && mob.Effects.IsStunned() {
+		g.broadcastMobAbilityLocked(mob, cast, "interrupted", 0)
+		mob.Ability = nil
+		return false
+	}
+
+	if !now.Before(cast.PhaseEnds) {
+		switch cast.Phase {
+		case AbilityWindUp:
+			cast.Phase = AbilityActive
+			cast.PhaseEnds = now.Add(seconds(ability.Active))
+			handler.Activate(g, mob, ability, cast, now)
+			g.broadcastMobAbilityLocked(mob, cast, string(cast.Phase), seconds(ability.Active))
+		case AbilityActive:
+			cast.Phase = AbilityRecovery
+			cast.PhaseEnds = now.Add(seconds(ability.Recovery))
+			g.broadcastMobAbilityLocked(mob, cast, string(cast.Phase), seconds(ability.Recovery))
+		default:
+			mob.Ability = nil
+			return false
+		}
+	}
+
+	switch cast.Phase {
+	case AbilityWindUp:
+		// Стоим на месте — замах должен быть читаемым
+		mob.TargetX, mob.TargetY = mob.X, mob.Y
+	case AbilityActive:
+		handler.Active(g, mob, ability, cast, now)
+	case AbilityRecovery:
+		handler.Recover(g, mob, ability, cast, now)
+	}
+	return true
+}
+
+// startMobAbilityLocked — начинает первую готовую способность, подходящую по дистанции
+func (g *Game) startMobAbilityLocked(mob *Mob, config MobConfig, target *Player, distance float64, now time.Time) bool {
+	// Способности только в бою: блуждающие и возвращающиеся мобы их не применяют
+	if target == nil || mob.State == MobStateWandering || mob.State == MobStateReturning || mob.Effects.IsStunned() {
+		return false
+	}
+
+	for _, name := range config.Abilities {
+		ability, ok := MobAbilities[name]
+		if !ok || distance < ability.MinRange || distance > ability.MaxRange {
+			continue
+		}
+		if now.Before(mob.AbilityCooldowns[name]) {
+			continue
+		}
+		if ability.Kind == AbilityKindStealPetal && (mob.Stolen != nil || len(target.GetActivePetals()) == 0) {
+			continue
+		}
+
+		if mob.AbilityCooldowns == nil {
+			mob.AbilityCooldowns = make(map[string]time.Time)
+		}
+		mob.AbilityCooldowns[name] = now.Add(seconds(ability.WindUp + ability.Cooldown))
+
+		mob.Ability = &MobAbilityCast{
+			Name:      name,
+			Phase:     AbilityWindUp,
+			TargetID:  target.ID,
+			X:         target.X,
+			Y:         target.Y,
+			PhaseEnds: now.Add(seconds(ability.WindUp)),
+		}
+		mob.State = MobStateAttacking
+		mob.TargetPlayer = target.ID
+		mob.TargetX, mob.TargetY = mob.X, mob.Y
+		g.broadcastMobAbilityLocked(mob, mob.Ability, string(AbilityWindUp), seconds(ability.WindUp))
+		return true
+	}
+	return false
+}
+
+// broadcastMobAbilityLocked — сообщает зоне о фазе способности моба
+func (g *Game) broadcastMobAbilityLocked(mob *Mob, cast *MobAbilityCast, phase string, duration time.Duration) {
+	g.broadcastToZoneLocked(mob.Zone, map[string]interface{}{
+		"type": "mob_ability",
+		"data": map[string]interface{}{
+			"mob_id":      mob.ID,
+			"ability":     cast.Name,
+			"phase":       phase,
+			"target_id":   cast.TargetID,
+			"x":           cast.X,
+			"y":           cast.Y,
+			"duration_ms": duration.Milliseconds(),
+		},
+	})
+}
+
+// mobHitPlayerLocked — урон игроку от способности моба
+func (g *Game) mobHitPlayerLocked(mob *Mob, player *Player, damage int, now time.Time) {
+	if !player.IsAlive() || !player.CanBeHitByMob() {
+		return
+	}
+	damage = scaleDamage(damage, player.StanceIncomingMultiplier())
+	damage = g.absorbPlayerDamage(player, damage)
+	if !player.TakeDamageFromMob(damage) {
+		return
+	}
+	player.Effects.ApplyAll(MobConfigs[mob.Type].OnHit, mob.ID, now)
+	g.sendDamageNotification(player, damage)
+	if !player.IsAlive() {
+		g.handlePlayerDeath(player)
+	}
+}
+
+type projectileAbility struct{}
+
+func (projectileAbility) Activate(g *Game, mob *Mob, ability MobAbility, cast *MobAbilityCast, now time.Time) {
+	angle := math.Atan2(cast.Y-mob.Y, cast.X-mob.X)
+	damage := scaleDamage(mob.Damage, ability.DamageMultiplier)
+	g.spawnMobProjectile(mob, angle, ability.Speed, damage, ability.MaxRange+50)
+}
+
+func (projectileAbility) Active(g *Game, mob *Mob, ability MobAbility, cast *MobAbilityCast, now time.Time) {
+}
+
+func (projectileAbility) Recover(g *Game, mob *Mob, ability MobAbility, cast *MobAbilityCast, now time.Time) {
+	mob.TargetX, mob.TargetY = mob.X, mob.Y
+}
+
+type lungeAbility struct{}
+
+func (lungeAbility) Activate(g *Game, mob *Mob, ability MobAbility, cast *MobAbilityCast, now time.Time) {
+	// Проскакиваем немного за точку прицеливания, чтобы рывок не обрывался у цели
+	angle := math.Atan2(cast.Y-mob.Y, cast.X-mob.X)
+	mob.TargetX = cast.X + math.Cos(angle)*mob.Radius
+	mob.TargetY = cast.Y + math.Sin(angle)*mob.Radius
+	mob.Speed = ability.Speed
+}
+
+func (lungeAbility) Active(g *Game, mob *Mob, ability MobAbility, cast *MobAbilityCast, now time.Time) {
+	if cast.Hit {
+		return
+	}
+	player := g.players[cast.TargetID]
+	if player == nil || player.CurrentZone != mob.Zone {
+		return
+	}
+	if player.DistanceTo(mob.X, mob.Y) <= mob.Radius+player.Radius {
+		cast.Hit = true
+		g.mobHitPlayerLocked(mob, player, scaleDamage(mob.Damage, ability.DamageMultiplier), now)
+	}
+}
+
+func (lungeAbility) Recover(g *Game, mob *Mob, ability MobAbility, cast *MobAbilityCast, now time.Time) {
+	mob.TargetX, mob.TargetY = mob.X, mob.Y
+}
+
+type stealAbility struct{}
+
+func (stealAbility) Activate(g *Game, mob *Mob, ability MobAbility, cast *MobAbilityCast, now time.Time) {
+	player := g.players[cast.TargetID]
+	if player == nil || player.CurrentZone != mob.Zone || mob.Stolen != nil {
+		return
+	}
+	if player.DistanceTo(mob.X, mob.Y) > ability.MaxRange+player.Radius {
+		return // цель успела отойти
+	}
+	petals := player.GetActivePetals()
+	if len(petals) == 0 {
+		return
+	}
+
+	// Лепесток выключается без перезарядки, пока его держит вор
+	petal := petals[0]
+	petal.IsActive = false
+	petal.ReloadStarted = time.Time{}
+	player.RecomputeFormation()
+
+	mob.Stolen = &StolenPetal{
+		OwnerID:  player.ID,
+		PetalID:  petal.ID,
+		Type:     petal.Type,
+		Releases: now.Add(seconds(ability.HoldTime)),
+	}
+
+	if conn, ok := g.connections[player.ID]; ok {
+		conn.WriteJSON(map[string]interface{}{
+			"type": "petal_stolen",
+			"data": map[string]interface{}{
+				"petal_id": petal.ID,
+				"type":     petal.Type,
+				"mob_id":   mob.ID,
+			},
+		})
+	}
+}
+
+func (stealAbility) Active(g *Game, mob *Mob, ability MobAbility, cast *MobAbilityCast, now time.Time) {
+}
+
+// Recover — вор убегает с добычей
+func (stealAbility) Recover(g *Game, mob *Mob, ability MobAbility, cast *MobAbilityCast, now time.Time) {
+	player := g.players[cast.TargetID]
+	if player == nil {
+		return
+	}
+	config := MobConfigs[mob.Type]
+	fleeFrom(mob, config, player, now)
+	if config.ChaseSpeed > 0 {
+		mob.Speed = chaseSpeedFor(mob, config)
+	}
+}
+
+// releaseStolenPetalLocked — возвращает украденный лепесток владельцу.
+// Если вора убили (killed), лепесток возвращается сразу, иначе по истечении
+// HoldTime он уходит на обычную перезарядку.
+func (g *Game) releaseStolenPetalLocked(mob *Mob, now time.Time, killed bool) {
+	stolen := mob.Stolen
+	if stolen == nil || (!killed && now.Before(stolen.Releases)) {
+		return
+	}
+	mob.Stolen = nil
+
+	player := g.players[stolen.OwnerID]
+	if player == nil {
+		return
+	}
+	petal := player.Petals[stolen.PetalID]
+	if petal == nil || petal.IsActive {
+		return
+	}
+
+	if killed && player.IsAlive() {
+		g.respawnPetal(player, petal)
+	} else {
+		petal.StartReload(now)
+	}
+	fmt.Printf("🌸 Petal %s returned to %s\n", petal.ID, player.ID)
+}
+
+// snapshot — копия для отправки клиенту
+func (c *MobAbilityCast) snapshot() *MobAbilityCast {
+	if c == nil {
+		return nil
+	}
+	copied := *c
+	return &copied
+}
+
+// snapshot — копия для отправки клиенту
+func (s *StolenPetal) snapshot() *StolenPetal {
+	if s == nil {
+		return nil
+	}
+	copied := *s
+	return &copied
+}
@@ -0,0 +1,296 @@
+package game
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MobBehaviorFSM — поведение, которое исполняет конечный автомат из контента
+const MobBehaviorFSM = "fsm"
+
+//go:embed content/mob_ai.json
+var defaultMobAI []byte
+
+// AIConditionDef — условие перехода. Value зависит от типа условия.
+type AIConditionDef struct {
+	Type  string  `json:"type"`
+	Value float64 `json:"value,omitempty"`
+	Not   bool    `json:"not,omitempty"`
+}
+
+// AITransitionDef — переход, срабатывает, когда выполнены все условия When
+type AITransitionDef struct {
+	To     string           `json:"to"`
+	Reason string           `json:"reason,omitempty"`
+	When   []AIConditionDef `json:"when"`
+}
+
+// AIStateDef — состояние автомата: действие каждый тик + переходы по порядку
+type AIStateDef struct {
+	Action      string            `json:"action"`
+	Transitions []AITransitionDef `json:"transitions"`
+}
+
+// AIMachineDef — автомат для одного типа мобов
+type AIMachineDef struct {
+	Initial string                `json:"initial"`
+	States  map[string]AIStateDef `json:"states"`
+}
+
+// aiContext — всё, что нужно условиям и действиям на текущем тике
+type aiContext struct {
+	g        *Game
+	mob      *Mob
+	config   MobConfig
+	player   *Player
+	distance float64
+	now      time.Time
+}
+
+type (
+	aiCondition func(ctx *aiContext, value float64) bool
+	aiAction    func(ctx *aiContext)
+)
+
+// Условия переходов
+var aiConditions = map[string]aiCondition{
+	"always": func(ctx *aiContext, value float64) bool { return true },
+	"player_in_range": func(ctx *aiContext, value float64) bool {
+		if value <= 0 {
+			value = ctx.mob.DetectionRange
+		}
+		return ctx.player != nil && ctx.distance <= value
+	},
+	"player_in_attack_range": func(ctx *aiContext, value float64) bool {
+		return ctx.player != nil && ctx.distance <= attackRangeFor(ctx.mob)
+	},
+	"health_below": func(ctx *aiContext, value float64) bool {
+		return float64(ctx.mob.Health) < float64(ctx.mob.MaxHealth)*value
+	},
+	"recently_hit": func(ctx *aiContext, value float64) bool {
+		return ctx.mob.IsRetaliating(ctx.now, seconds(value))
+	},
+	"has_threat": func(ctx *aiContext, value float64) bool {
+		return ctx.player != nil && ctx.mob.Threat[ctx.player.ID] > value
+	},
+	"ally_nearby": func(ctx *aiContext, value float64) bool {
+		return len(ctx.g.alliesNearLocked(ctx.mob, value)) > 0
+	},
+	"in_group": func(ctx *aiContext, value float64) bool {
+		return ctx.g.groupOf(ctx.mob) != nil
+	},
+	"group_engaged": func(ctx *aiContext, value float64) bool {
+		group := ctx.g.groupOf(ctx.mob)
+		return group != nil && ctx.now.Sub(group.Engaged) <= seconds(value)
+	},
+	"help_called": func(ctx *aiContext, value float64) bool {
+		return ctx.player != nil && !ctx.mob.HelpCalledAt.IsZero() && ctx.now.Sub(ctx.mob.HelpCalledAt) <= seconds(value)
+	},
+}
+
+// Действия состояний
+var aiActions = map[string]aiAction{
+	"wander": func(ctx *aiContext) {
+		wander(ctx.mob, ctx.config, ctx.now)
+	},
+	"chase": func(ctx *aiContext) {
+		if ctx.player != nil {
+			chasePlayer(ctx.mob, ctx.config, ctx.player, ctx.distance, ctx.now)
+		}
+	},
+	"attack": func(ctx *aiContext) {
+		if ctx.player != nil {
+			attackPlayer(ctx.mob, ctx.config, ctx.player, ctx.now)
+		}
+	},
+	"flee": func(ctx *aiContext) {
+		if ctx.player != nil {
+			fleeFrom(ctx.mob, ctx.config, ctx.player, ctx.now)
+		}
+	},
+	"surround": func(ctx *aiContext) {
+		if ctx.player != nil {
+			ctx.g.surroundPlayer(ctx.mob, ctx.config, ctx.player, ctx.distance, ctx.now)
+		}
+	},
+	"scatter": func(ctx *aiContext) {
+		if ctx.player != nil {
+			ctx.g.scatterFrom(ctx.mob, ctx.config, ctx.player, ctx.now)
+		}
+	},
+	"call_for_help": func(ctx *aiContext) {
+		if ctx.player == nil {
+			return
+		}
+		// Зовём соседей того же типа и всю свою группу, где бы она ни была
+		allies := ctx.g.alliesNearLocked(ctx.mob, HelpCallRadius)
+		if group := ctx.g.groupOf(ctx.mob); group != nil {
+			for _, id := range group.Members {
+				if member := ctx.g.mobs[id]; member != nil && member.ID != ctx.mob.ID {
+					allies = append(allies, member)
+				}
+			}
+		}
+		for _, ally := range allies {
+			ally.HelpCalledAt = ctx.now
+			ally.TargetPlayer = ctx.player.ID
+			ctx.g.addThreatLocked(ally, ctx.player.ID, ThreatMin*2)
+		}
+	},
+}
+
+// HelpCallRadius — радиус, в котором союзники слышат зов о помощи
+const HelpCallRadius = 300.0
+
+// mobAIMachines — автоматы ИИ по имени (подменяется под contentMu, читать через lookupMobAI)
+var mobAIMachines = map[string]AIMachineDef{}
+
+func init() {
+	if err := LoadMobAI(defaultMobAI); err != nil {
+		panic(err)
+	}
+	RegisterMobBehavior(MobBehaviorFSM, fsmBehavior{})
+}
+
+// LoadMobAI разбирает и проверяет описание автоматов и заменяет текущие
+func LoadMobAI(data []byte) error {
+	machines, err := parseMobAI(data)
+	if err != nil {
+		return err
+	}
+	contentMu.Lock()
+	mobAIMachines = machines
+	contentMu.Unlock()
+	return nil
+}
+
+// parseMobAI разбирает и проверяет описание автоматов, ничего не меняя
+func parseMobAI(data []byte) (map[string]AIMachineDef, error) {
+	var machines map[string]AIMachineDef
+	if err := json.Unmarshal(data, &machines); err != nil {
+		return nil, fmt.Errorf("mob ai: %w", err)
+	}
+
+	for name, machine := range machines {
+		if err := validateMachine(machine); err != nil {
+			return nil, fmt.Errorf("mob ai %q: %w", name, err)
+		}
+	}
+	return machines, nil
+}
+
+func validateMachine(machine AIMachineDef) error {
+	if _, ok := machine.States[machine.Initial]; !ok {
+		return fmt.Errorf("unknown initial state %q", machine.Initial)
+	}
+	for stateName, state := range machine.States {
+		if _, ok := aiActions[state.Action]; !ok {
+			return fmt.Errorf("state %q: unknown action %q", stateName, state.Action)
+		}
+		for _, tr := range state.Transitions {
+			if _, ok := machine.States[tr.To]; !ok {
+				return fmt.Errorf("state %q: transition to unknown state %q", stateName, tr.To)
+			}
+			for _, cond := range tr.When {
+				if _, ok := aiConditions[cond.Type]; !ok {
+					return fmt.Errorf("state %q: unknown condition %q", stateName, cond.Type)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+type fsmBehavior struct{}
+
+func (fsmBehavior) Update(g *Game, mob *Mob, config MobConfig, player *Player, distance float64, now time.Time) {
+	machine, ok := lookupMobAI(config.AI)
+	if !ok {
+		return
+	}
+
+	if _, ok := machine.States[mob.AIState]; !ok {
+		mob.AIState = machine.Initial
+		mob.AIReason = "initial"
+		mob.AIStateSince = now
+	}
+
+	ctx := &aiContext{g: g, mob: mob, config: config, player: player, distance: distance, now: now}
+
+	// Проверяем переходы текущего состояния по порядку — срабатывает первый
+	for _, tr := range machine.States[mob.AIState].Transitions {
+		if !ctx.matches(tr.When) {
+			continue
+		}
+		mob.AIState = tr.To
+		mob.AIReason = tr.Reason
+		if mob.AIReason == "" {
+			mob.AIReason = "→ " + tr.To
+		}
+		mob.AIStateSince = now
+		break
+	}
+
+	aiActions[machine.States[mob.AIState].Action](ctx)
+	g.sendMobAIDebugLocked(mob, now)
+}
+
+func (ctx *aiContext) matches(conditions []AIConditionDef) bool {
+	for _, cond := range conditions {
+		if aiConditions[cond.Type](ctx, cond.Value) == cond.Not {
+			return false
+		}
+	}
+	return true
+}
+
+// alliesNearLocked — живые мобы того же типа в радиусе
+func (g *Game) alliesNearLocked(mob *Mob, radius float64) []*Mob {
+	allies := make([]*Mob, 0)
+	for _, other := range g.mobs {
+		if other.ID == mob.ID || other.Zone != mob.Zone || other.Type != mob.Type || !other.IsAlive() {
+			continue
+		}
+		if other.DistanceTo(mob.X, mob.Y) <= radius {
+			allies = append(allies, other)
+		}
+	}
+	return allies
+}
+
+// WatchMobAI — подписывает игрока на отладочный поток состояния моба
+// (пустой mobID — отписаться)
+func (g *Game) WatchMobAI(playerID, mobID string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if mobID == "" {
+		delete(g.aiWatchers, playerID)
+		return
+	}
+	g.aiWatchers[playerID] = mobID
+}
+
+// sendMobAIDebugLocked — отправляет состояние автомата подписанным игрокам
+func (g *Game) sendMobAIDebugLocked(mob *Mob, now time.Time) {
+	for playerID, mobID := range g.aiWatchers {
+		if mobID != mob.ID {
+			continue
+		}
+		if conn, ok := g.connections[playerID]; ok {
+			conn.WriteJSON(map[string]interface{}{
+				"type": "mob_ai_debug",
+				"data": map[string]interface{}{
+					"mob_id":   mob.ID,
+					"state":    mob.AIState,
+					"reason":   mob.AIReason,
+					"since_ms": now.Sub(mob.AIStateSince).Milliseconds(),
+					"target":   mob.TargetPlayer,
+					"health":   mob.Health,
+				},
+			})
+		}
+	}
+}
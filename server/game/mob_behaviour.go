@@ -101,19 +101,29 @@ func (g *Game) adjustMobTargets(mobA, mobB *Mob, dx, dy, shift float64) {
 func (g *Game) updateMobBehavior(mob *Mob) {
 	now := time.Now()
 
-	// Находим ближайшего игрока в зоне
-	closestPlayer, distance := g.findClosestPlayerInZoneLocked(mob.X, mob.Y, mob.Zone)
+	config := MobConfigs[mob.Type]
+
+	// Выбираем цель по таблице угрозы
+	g.updateThreatLocked(mob, config, 0.1)
+	target, distance := g.selectTargetLocked(mob)
 
 	// Проверяем коллизии с другими мобами перед обновлением поведения
 	g.avoidOtherMobsLocked(mob)
 
-	switch mob.Type {
-	case MobTypeOrc:
-		g.updateOrcBehavior(mob, closestPlayer, distance, now)
-	case MobTypeWolf:
-		g.updateWolfBehavior(mob, closestPlayer, distance, now)
-	case MobTypeGoblin:
-		g.updateGoblinBehavior(mob, closestPlayer, distance, now)
+	// Утянутый за поводок моб идёт домой и не реагирует на игроков
+	if g.updateLeashLocked(mob, config, now, 0.1) {
+		g.moveMobLocked(mob)
+		return
+	}
+
+	// Способность в процессе (замах, действие, восстановление) заменяет поведение
+	if g.updateMobAbilityLocked(mob, config, target, distance, now) {
+		g.moveMobLocked(mob)
+		return
+	}
+
+	if behavior, ok := mobBehaviors[config.Behavior]; ok {
+		behavior.Update(g, mob, config, target, distance, now)
 	}
 
 	// Применяем движение
@@ -141,112 +151,166 @@ func (g *Game) avoidOtherMobsLocked(mob *Mob) {
 }
 
 
-func (g *Game) updateOrcBehavior(mob *Mob, player *Player, distance float64, now time.Time) {
-	// Базовые скорости (настроить под ваш геймплей)
-	const baseWanderSpeed = 0.8
-	const baseChaseSpeed = 18
+// Имена поведений мобов (MobConfigs[...].Behavior)
+const (
+	MobBehaviorAggressive = "aggressive" // преследует и атакует игрока
+	MobBehaviorNeutral    = "neutral"    // просто бродит
+	MobBehaviorCoward     = "coward"     // убегает от игрока
+)
 
-	if player != nil && distance <= mob.DetectionRange {
-		if mob.State != MobStateChasing && mob.State != MobStateAttacking {
-			mob.State = MobStateChasing
-			mob.TargetPlayer = player.ID
-		}
+// MobBehavior — подключаемое поведение моба. Update вызывается каждый тик
+// под g.mu и должен выставить State и цель движения (TargetX/TargetY).
+type MobBehavior interface {
+	Update(g *Game, mob *Mob, config MobConfig, player *Player, distance float64, now time.Time)
+}
 
-		// Вычисляем дистанцию атаки в переменной
-		attackRange := mob.Radius + PlayerRadius + 10
+var mobBehaviors = map[string]MobBehavior{}
 
-		if distance <= attackRange {
-			// Атака
-			if now.After(mob.AttackCooldown) {
-				mob.State = MobStateAttacking
-				mob.AttackCooldown = now.Add(2 * time.Second)
+// RegisterMobBehavior регистрирует поведение под именем из MobConfigs.
+// Новый тип моба = запись в MobConfigs + (при необходимости) новое поведение.
+func RegisterMobBehavior(name string, behavior MobBehavior) {
+	mobBehaviors[name] = behavior
+}
 
-				angle := math.Atan2(player.Y-mob.Y, player.X-mob.X)
-				mob.TargetX = player.X - math.Cos(angle)*(mob.Radius+PlayerRadius+5)
-				mob.TargetY = player.Y - math.Sin(angle)*(mob.Radius+PlayerRadius+5)
+func init() {
+	RegisterMobBehavior(MobBehaviorAggressive, aggressiveBehavior{})
+	RegisterMobBehavior(MobBehaviorNeutral, neutralBehavior{})
+	RegisterMobBehavior(MobBehaviorCoward, cowardBehavior{})
+}
 
-				// Сбрасываем скорость при атаке
-				mob.Speed = baseChaseSpeed
-			}
-		} else {
-			// Преследование с улучшенным зигзагом
-			mob.State = MobStateChasing
-
-			if now.Sub(mob.LastMoveTime) > 300*time.Millisecond {
-				baseAngle := math.Atan2(player.Y-mob.Y, player.X-mob.X)
-
-				// Время для плавных волн
-				elapsed := now.Sub(mob.CreationTime).Seconds()
-
-				// Многократные волны для сложного паттерна
-				sinWave1 := math.Sin(elapsed*3) * 0.8
-				sinWave2 := math.Sin(elapsed*1.5) * 1.2
-				cosWave := math.Cos(elapsed*2) * 0.6
-
-				// Комбинируем волны для сложного паттерна
-				deviation := (sinWave1 + sinWave2 + cosWave) * 0.4
-
-				// Добавляем случайный элемент для непредсказуемости
-				randomFactor := (rand.Float64() - 0.5) * 0.3
-				finalDeviation := deviation + randomFactor
-
-				// Применяем отклонение
-				finalAngle := baseAngle + finalDeviation
-
-				// Дистанция до цели зависит от расстояния до игрока
-				targetDistance := distance * 0.3
-				if targetDistance > 100 {
-					targetDistance = 100
-				}
-				if targetDistance < 40 {
-					targetDistance = 40
-				}
-
-				mob.TargetX = player.X - math.Cos(finalAngle)*targetDistance
-				mob.TargetY = player.Y - math.Sin(finalAngle)*targetDistance
-				mob.LastMoveTime = now
-
-				// Динамическая скорость для эффекта "завихрения"
-				speedVariation := math.Abs(sinWave1) * 0.6
-				mob.Speed = baseChaseSpeed + speedVariation
-			}
-		}
-	} else {
-		// Блуждание
-		mob.State = MobStateWandering
-		mob.TargetPlayer = ""
-		mob.Speed = baseWanderSpeed // Меньшая скорость при блуждании
+// seconds — перевод секунд из конфига в time.Duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
 
-		if now.Sub(mob.LastMoveTime) > 3*time.Second {
-			mob.SetRandomTarget()
-		}
+// wander — общее блуждание для всех поведений
+func wander(mob *Mob, config MobConfig, now time.Time) {
+	mob.State = MobStateWandering
+	mob.TargetPlayer = ""
+	if config.WanderSpeed > 0 {
+		mob.Speed = config.WanderSpeed // Меньшая скорость при блуждании
+	}
+
+	if now.Sub(mob.LastMoveTime) > seconds(config.WanderInterval) {
+		mob.SetRandomTarget()
+	}
+}
+
+// attackRangeFor — дистанция, с которой моб делает рывок в атаку
+func attackRangeFor(mob *Mob) float64 {
+	return mob.Radius + PlayerRadius + 10
+}
+
+// chaseSpeedFor — скорость преследования (одинакова для всех редкостей,
+// как и до реестра поведений)
+func chaseSpeedFor(mob *Mob, config MobConfig) float64 {
+	return config.ChaseSpeed
+}
+
+// attackPlayer — рывок вплотную к игроку, если прошёл кулдаун атаки
+func attackPlayer(mob *Mob, config MobConfig, player *Player, now time.Time) {
+	mob.TargetPlayer = player.ID
+	if !now.After(mob.AttackCooldown) {
+		return
+	}
+
+	mob.State = MobStateAttacking
+	mob.AttackCooldown = now.Add(seconds(config.AttackCooldown))
+
+	angle := math.Atan2(player.Y-mob.Y, player.X-mob.X)
+	mob.TargetX = player.X - math.Cos(angle)*(mob.Radius+PlayerRadius+5)
+	mob.TargetY = player.Y - math.Sin(angle)*(mob.Radius+PlayerRadius+5)
+
+	// Сбрасываем скорость при атаке
+	mob.Speed = chaseSpeedFor(mob, config)
+}
+
+// chasePlayer — преследование игрока с зигзагом
+func chasePlayer(mob *Mob, config MobConfig, player *Player, distance float64, now time.Time) {
+	mob.State = MobStateChasing
+	mob.TargetPlayer = player.ID
+
+	if now.Sub(mob.LastMoveTime) <= seconds(config.RetargetDelay) {
+		return
+	}
+
+	baseAngle := math.Atan2(player.Y-mob.Y, player.X-mob.X)
+
+	// Время для плавных волн
+	elapsed := now.Sub(mob.CreationTime).Seconds()
+
+	// Многократные волны для сложного паттерна
+	sinWave1 := math.Sin(elapsed*3) * 0.8
+	sinWave2 := math.Sin(elapsed*1.5) * 1.2
+	cosWave := math.Cos(elapsed*2) * 0.6
+
+	// Комбинируем волны и добавляем случайный элемент для непредсказуемости
+	deviation := (sinWave1 + sinWave2 + cosWave) * config.ZigzagAmplitude
+	randomFactor := (rand.Float64() - 0.5) * 0.75 * config.ZigzagAmplitude
+	finalAngle := baseAngle + deviation + randomFactor
+
+	// Дистанция до цели зависит от расстояния до игрока
+	targetDistance := distance * 0.3
+	if targetDistance > 100 {
+		targetDistance = 100
+	}
+	if targetDistance < 40 {
+		targetDistance = 40
+	}
+
+	mob.TargetX = player.X - math.Cos(finalAngle)*targetDistance
+	mob.TargetY = player.Y - math.Sin(finalAngle)*targetDistance
+	mob.LastMoveTime = now
+
+	// Динамическая скорость для эффекта "завихрения"
+	speedVariation := math.Abs(sinWave1) * 0.6
+	mob.Speed = chaseSpeedFor(mob, config) + speedVariation
+}
+
+// fleeFrom — убегает от игрока
+func fleeFrom(mob *Mob, config MobConfig, player *Player, now time.Time) {
+	mob.State = MobStateFleeing
+	angle := math.Atan2(mob.Y-player.Y, mob.X-player.X)
+	mob.TargetX = mob.X + math.Cos(angle)*config.FleeDistance
+	mob.TargetY = mob.Y + math.Sin(angle)*config.FleeDistance
+	mob.LastMoveTime = now
+}
+
+type aggressiveBehavior struct{}
+
+func (aggressiveBehavior) Update(g *Game, mob *Mob, config MobConfig, player *Player, distance float64, now time.Time) {
+	if player == nil || distance > mob.DetectionRange {
+		wander(mob, config, now)
+		return
+	}
+
+	if distance <= attackRangeFor(mob) {
+		attackPlayer(mob, config, player, now)
+	} else {
+		chasePlayer(mob, config, player, distance, now)
 	}
 }
 
-func (g *Game) updateWolfBehavior(mob *Mob, player *Player, distance float64, now time.Time) {
+type neutralBehavior struct{}
+
+func (neutralBehavior) Update(g *Game, mob *Mob, config MobConfig, player *Player, distance float64, now time.Time) {
 	// Нейтральное поведение - просто бродит
-	if mob.State != MobStateWandering || now.Sub(mob.LastMoveTime) > 3*time.Second {
+	if mob.State != MobStateWandering || now.Sub(mob.LastMoveTime) > seconds(config.WanderInterval) {
 		mob.State = MobStateWandering
 		mob.SetRandomTarget()
 	}
 }
 
-func (g *Game) updateGoblinBehavior(mob *Mob, player *Player, distance float64, now time.Time) {
-	if player != nil && distance <= mob.DetectionRange {
-		mob.State = MobStateFleeing
-		// Убегает от игрока
-		angle := math.Atan2(mob.Y-player.Y, mob.X-player.X)
-		fleeDistance := 200.0
+type cowardBehavior struct{}
 
-		mob.TargetX = mob.X + math.Cos(angle)*fleeDistance
-		mob.TargetY = mob.Y + math.Sin(angle)*fleeDistance
-		mob.LastMoveTime = now
-	} else {
-		mob.State = MobStateWandering
-		if now.Sub(mob.LastMoveTime) > 3*time.Second {
-			mob.SetRandomTarget()
-		}
+func (cowardBehavior) Update(g *Game, mob *Mob, config MobConfig, player *Player, distance float64, now time.Time) {
+	if player == nil || distance > mob.DetectionRange {
+		wander(mob, config, now)
+		return
 	}
+
+	// Убегает от игрока
+	fleeFrom(mob, config, player, now)
 }
 
 func (g *Game) moveMobLocked(mob *Mob) {
@@ -254,8 +318,11 @@ func (g *Game) moveMobLocked(mob *Mob) {
 		return
 	}
 
-	dx := mob.TargetX - mob.X
-	dy := mob.TargetY - mob.Y
+	// В обход препятствий — к ближайшей точке маршрута
+	steerX, steerY := g.steerLocked(mob, mob.TargetX, mob.TargetY, time.Now())
+
+	dx := steerX - mob.X
+	dy := steerY - mob.Y
 	distSq := dx*dx + dy*dy
 
 	if distSq > 25 { // 5*5
@@ -263,10 +330,12 @@ func (g *Game) moveMobLocked(mob *Mob) {
 		dx /= distance
 		dy /= distance
 
-		newX := mob.X + dx*mob.Speed
-		newY := mob.Y + dy*mob.Speed
+		speed := mob.Speed * mob.Effects.SpeedMultiplier() * mob.AffixSpeedMultiplier()
+		newX := mob.X + dx*speed
+		newY := mob.Y + dy*speed
 
 		newX, newY = g.constrainMobToZone(mob, newX, newY)
+		newX, newY = g.resolveObstaclesLocked(mob.Zone, newX, newY, mob.Radius, false)
 		mob.X = newX
 		mob.Y = newY
 	}
@@ -279,7 +348,7 @@ func (g *Game) findClosestPlayerInZoneLocked(x, y float64, zone string) (*Player
 
 	for _, player := range g.players {
 		// Пропускаем мёртвых игроков
-		if player.CurrentZone == zone && player.IsAlive() {
+		if player.CurrentZone == zone && player.IsAlive() && !player.InSafeZone {
 			dx := x - player.X
 			dy := y - player.Y
 			dist := dx*dx + dy*dy
@@ -0,0 +1,143 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Параметры групп мобов
+const (
+	MinGroupSize       = 2     // группа из меньшего числа мобов распадается
+	GroupSpawnSpread   = 120.0 // разброс спавна членов группы вокруг центра
+	GroupThreatShare   = 0.5   // доля угрозы, которую получают остальные члены группы
+	ScatterSpreadAngle = 1.2   // на сколько радиан разлетаются убегающие члены группы
+)
+
+// MobGroup — группа мобов одного типа, заспавненных вместе
+type MobGroup struct {
+	ID      string
+	Type    MobType
+	Zone    string
+	Members []string // ID мобов в порядке вступления (индекс = слот в строю)
+	Engaged time.Time
+}
+
+// newMobGroupLocked — создаёт группу (вызывается под g.mu)
+func (g *Game) newMobGroupLocked(mobType MobType, zone string) *MobGroup {
+	g.entitySeq++
+	group := &MobGroup{
+		ID:   fmt.Sprintf("group_%s_%d", zone, g.entitySeq),
+		Type: mobType,
+		Zone: zone,
+	}
+	g.mobGroups[group.ID] = group
+	return group
+}
+
+// addMember — добавляет моба в группу
+func (grp *MobGroup) addMember(mob *Mob) {
+	grp.Members = append(grp.Members, mob.ID)
+	mob.GroupID = grp.ID
+}
+
+// slot — порядковый номер моба в группе и размер группы
+func (grp *MobGroup) slot(mobID string) (int, int) {
+	for i, id := range grp.Members {
+		if id == mobID {
+			return i, len(grp.Members)
+		}
+	}
+	return 0, len(grp.Members)
+}
+
+// groupOf — группа моба или nil
+func (g *Game) groupOf(mob *Mob) *MobGroup {
+	if mob.GroupID == "" {
+		return nil
+	}
+	return g.mobGroups[mob.GroupID]
+}
+
+// removeFromGroupLocked — убирает погибшего моба из группы; слишком
+// маленькая группа распадается, оставшиеся становятся одиночками
+func (g *Game) removeFromGroupLocked(mob *Mob) {
+	group := g.groupOf(mob)
+	if group == nil {
+		return
+	}
+
+	members := group.Members[:0]
+	for _, id := range group.Members {
+		if id != mob.ID {
+			members = append(members, id)
+		}
+	}
+	group.Members = members
+
+	if len(group.Members) >= MinGroupSize {
+		return
+	}
+	for _, id := range group.Members {
+		if member := g.mobs[id]; member != nil {
+			member.GroupID = ""
+		}
+	}
+	delete(g.mobGroups, group.ID)
+}
+
+// shareThreatLocked — удар по члену группы настраивает против игрока всю группу
+func (g *Game) shareThreatLocked(mob *Mob, playerID string, amount float64, now time.Time) {
+	group := g.groupOf(mob)
+	if group == nil {
+		return
+	}
+	group.Engaged = now
+	for _, id := range group.Members {
+		if member := g.mobs[id]; member != nil && member.ID != mob.ID && member.IsAlive() {
+			g.addThreatLocked(member, playerID, amount*GroupThreatShare)
+		}
+	}
+}
+
+// surroundPlayer — стая занимает позиции по кругу вокруг цели
+func (g *Game) surroundPlayer(mob *Mob, config MobConfig, player *Player, distance float64, now time.Time) {
+	group := g.groupOf(mob)
+	if group == nil {
+		chasePlayer(mob, config, player, distance, now)
+		return
+	}
+
+	mob.State = MobStateChasing
+	mob.TargetPlayer = player.ID
+
+	// Каждый член стаи заходит со своей стороны на дистанцию атаки
+	index, size := group.slot(mob.ID)
+	angle := 2 * math.Pi * float64(index) / float64(size)
+	radius := attackRangeFor(mob) - 5
+	mob.TargetX = player.X + math.Cos(angle)*radius
+	mob.TargetY = player.Y + math.Sin(angle)*radius
+	mob.Speed = chaseSpeedFor(mob, config)
+	mob.LastMoveTime = now
+}
+
+// scatterFrom — члены группы разбегаются от игрока в разные стороны
+func (g *Game) scatterFrom(mob *Mob, config MobConfig, player *Player, now time.Time) {
+	group := g.groupOf(mob)
+	if group == nil {
+		fleeFrom(mob, config, player, now)
+		return
+	}
+
+	index, size := group.slot(mob.ID)
+	offset := 0.0
+	if size > 1 {
+		offset = (float64(index)/float64(size-1) - 0.5) * 2 * ScatterSpreadAngle
+	}
+
+	mob.State = MobStateFleeing
+	angle := math.Atan2(mob.Y-player.Y, mob.X-player.X) + offset
+	mob.TargetX = mob.X + math.Cos(angle)*config.FleeDistance
+	mob.TargetY = mob.Y + math.Sin(angle)*config.FleeDistance
+	mob.LastMoveTime = now
+}
@@ -0,0 +1,328 @@
+package game
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Параметры навигации
+const (
+	NavCellSize      = 40.0 // размер клетки сетки проходимости
+	NavClearance     = 20.0 // запас вокруг препятствий при построении сетки
+	NavReplanDelay   = 500 * time.Millisecond
+	NavPathCacheTTL  = 1 * time.Second
+	NavWaypointReach = 15.0 // на каком расстоянии точка маршрута считается пройденной
+	NavMaxExpanded   = 20000
+)
+
+// navCell — координаты клетки сетки
+type navCell struct{ X, Y int }
+
+// navGrid — сетка проходимости одной зоны
+type navGrid struct {
+	MinX, MinY float64
+	Cols, Rows int
+	Blocked    []bool
+}
+
+// navKey — ключ кэша маршрутов: мобы из одной клетки к одной цели делят маршрут
+type navKey struct {
+	Zone        string
+	Start, Goal navCell
+}
+
+type cachedPath struct {
+	Points  []Point
+	Created time.Time
+}
+
+// initNavigation — строит сетки проходимости для зон с препятствиями
+func (g *Game) initNavigation() {
+	for name, zone := range g.zones {
+		if len(zone.Obstacles) == 0 {
+			continue
+		}
+		g.navGrids[name] = buildNavGrid(zone)
+	}
+	fmt.Println("✅ Navigation grids built")
+}
+
+func buildNavGrid(zone *Zone) *navGrid {
+	grid := &navGrid{
+		MinX: zone.MinX,
+		MinY: zone.MinY,
+		Cols: int(math.Ceil((zone.MaxX-zone.MinX)/NavCellSize)) + 1,
+		Rows: int(math.Ceil((zone.MaxY-zone.MinY)/NavCellSize)) + 1,
+	}
+	grid.Blocked = make([]bool, grid.Cols*grid.Rows)
+	for y := 0; y < grid.Rows; y++ {
+		for x := 0; x < grid.Cols; x++ {
+			cx, cy := grid.center(navCell{x, y})
+			for _, o := range zone.Obstacles {
+				if o.Overlaps(cx, cy, NavClearance) {
+					grid.Blocked[y*grid.Cols+x] = true
+					break
+				}
+			}
+		}
+	}
+	return grid
+}
+
+func (n *navGrid) cellAt(x, y float64) navCell {
+	cx := int((x - n.MinX) / NavCellSize)
+	cy := int((y - n.MinY) / NavCellSize)
+	cx = max(0, min(n.Cols-1, cx))
+	cy = max(0, min(n.Rows-1, cy))
+	return navCell{cx, cy}
+}
+
+func (n *navGrid) center(c navCell) (float64, float64) {
+	return n.MinX + (float64(c.X)+0.5)*NavCellSize, n.MinY + (float64(c.Y)+0.5)*NavCellSize
+}
+
+func (n *navGrid) blocked(c navCell) bool {
+	if c.X < 0 || c.Y < 0 || c.X >= n.Cols || c.Y >= n.Rows {
+		return true
+	}
+	return n.Blocked[c.Y*n.Cols+c.X]
+}
+
+// lineOfSight — можно ли пройти по прямой от (x1, y1) до (x2, y2)
+func (n *navGrid) lineOfSight(x1, y1, x2, y2 float64) bool {
+	dx, dy := x2-x1, y2-y1
+	steps := int(math.Ceil(math.Sqrt(dx*dx+dy*dy) / (NavCellSize / 2)))
+	for i := 0; i <= steps; i++ {
+		t := 1.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		if n.blocked(n.cellAt(x1+dx*t, y1+dy*t)) {
+			return false
+		}
+	}
+	return true
+}
+
+// nearestOpen — ближайшая проходимая клетка (цель или старт внутри препятствия)
+func (n *navGrid) nearestOpen(c navCell) (navCell, bool) {
+	if !n.blocked(c) {
+		return c, true
+	}
+	for r := 1; r <= 10; r++ {
+		for dy := -r; dy <= r; dy++ {
+			for dx := -r; dx <= r; dx++ {
+				if abs(dx) != r && abs(dy) != r {
+					continue
+				}
+				if candidate := (navCell{c.X + dx, c.Y + dy}); !n.blocked(candidate) {
+					return candidate, true
+				}
+			}
+		}
+	}
+	return c, false
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// findPath — A* по 8 направлениям без срезания углов препятствий.
+// Возвращает сглаженный маршрут без стартовой точки.
+func (n *navGrid) findPath(sx, sy, gx, gy float64) []Point {
+	start, ok := n.nearestOpen(n.cellAt(sx, sy))
+	if !ok {
+		return nil
+	}
+	goal, ok := n.nearestOpen(n.cellAt(gx, gy))
+	if !ok {
+		return nil
+	}
+
+	index := func(c navCell) int { return c.Y*n.Cols + c.X }
+	heuristic := func(c navCell) float64 {
+		dx, dy := math.Abs(float64(c.X-goal.X)), math.Abs(float64(c.Y-goal.Y))
+		return dx + dy + (math.Sqrt2-2)*math.Min(dx, dy)
+	}
+
+	// Плотные срезы по индексу клетки: поиск идёт под g.mu и может обойти
+	// всю зону, а карты на каждый поиск заметно дороже
+	size := n.Cols * n.Rows
+	cost := make([]float64, size)
+	for i := range cost {
+		cost[i] = math.Inf(1)
+	}
+	cost[index(start)] = 0
+	from := make([]navCell, size)
+	closed := make([]bool, size)
+	open := &navHeap{{Cell: start, F: heuristic(start)}}
+
+	found := false
+	expanded := 0
+	for open.Len() > 0 && expanded < NavMaxExpanded {
+		current := heap.Pop(open).(navNode).Cell
+		ci := index(current)
+		if closed[ci] {
+			continue
+		}
+		if current == goal {
+			found = true
+			break
+		}
+		closed[ci] = true
+		expanded++
+
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if dx == 0 && dy == 0 {
+					continue
+				}
+				if !n.canStep(current, dx, dy) {
+					continue
+				}
+				next := navCell{current.X + dx, current.Y + dy}
+				step := 1.0
+				if dx != 0 && dy != 0 {
+					step = math.Sqrt2
+				}
+				ni := index(next)
+				newCost := cost[ci] + step
+				if cost[ni] <= newCost {
+					continue
+				}
+				cost[ni] = newCost
+				from[ni] = current
+				heap.Push(open, navNode{Cell: next, F: newCost + heuristic(next)})
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	// Восстанавливаем маршрут от цели к старту
+	cells := []navCell{goal}
+	for c := goal; c != start; {
+		c = from[index(c)]
+		cells = append(cells, c)
+	}
+	points := make([]Point, 0, len(cells)+1)
+	points = append(points, Point{sx, sy})
+	for i := len(cells) - 2; i >= 0; i-- {
+		x, y := n.center(cells[i])
+		points = append(points, Point{x, y})
+	}
+	if !n.blocked(n.cellAt(gx, gy)) {
+		points[len(points)-1] = Point{gx, gy}
+	}
+	return n.smooth(points)[1:]
+}
+
+// canStep — можно ли шагнуть из c на (dx, dy). Диагональ — только если
+// оба соседних прямых хода свободны (углы препятствий не срезаются).
+func (n *navGrid) canStep(c navCell, dx, dy int) bool {
+	if n.blocked(navCell{c.X + dx, c.Y + dy}) {
+		return false
+	}
+	if dx != 0 && dy != 0 && (n.blocked(navCell{c.X + dx, c.Y}) || n.blocked(navCell{c.X, c.Y + dy})) {
+		return false
+	}
+	return true
+}
+
+// smooth — убирает промежуточные точки, между которыми есть прямая видимость
+func (n *navGrid) smooth(points []Point) []Point {
+	if len(points) <= 2 {
+		return points
+	}
+	smoothed := []Point{points[0]}
+	anchor := 0
+	for i := 2; i < len(points); i++ {
+		a, b := points[anchor], points[i]
+		if !n.lineOfSight(a.X, a.Y, b.X, b.Y) {
+			anchor = i - 1
+			smoothed = append(smoothed, points[anchor])
+		}
+	}
+	return append(smoothed, points[len(points)-1])
+}
+
+// navNode / navHeap — очередь с приоритетом для A*
+type navNode struct {
+	Cell navCell
+	F    float64
+}
+
+type navHeap []navNode
+
+func (h navHeap) Len() int            { return len(h) }
+func (h navHeap) Less(i, j int) bool  { return h[i].F < h[j].F }
+func (h navHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *navHeap) Push(x interface{}) { *h = append(*h, x.(navNode)) }
+func (h *navHeap) Pop() interface{} {
+	old := *h
+	node := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return node
+}
+
+// pathLocked — маршрут из кэша или новый (вызывается под g.mu)
+func (g *Game) pathLocked(zone string, grid *navGrid, sx, sy, gx, gy float64, now time.Time) []Point {
+	key := navKey{Zone: zone, Start: grid.cellAt(sx, sy), Goal: grid.cellAt(gx, gy)}
+	if cached, ok := g.pathCache[key]; ok && now.Sub(cached.Created) < NavPathCacheTTL {
+		return cached.Points
+	}
+
+	// Заодно чистим устаревшие записи
+	for k, cached := range g.pathCache {
+		if now.Sub(cached.Created) >= NavPathCacheTTL {
+			delete(g.pathCache, k)
+		}
+	}
+
+	points := grid.findPath(sx, sy, gx, gy)
+	g.pathCache[key] = &cachedPath{Points: points, Created: now}
+	return points
+}
+
+// steerLocked — куда моб должен идти на этом тике, чтобы добраться до
+// (targetX, targetY): сама цель при прямой видимости, иначе следующая точка маршрута.
+// Маршруты строятся только при преследовании и возвращении домой.
+func (g *Game) steerLocked(mob *Mob, targetX, targetY float64, now time.Time) (float64, float64) {
+	grid := g.navGrids[mob.Zone]
+	if grid == nil || (mob.State != MobStateChasing && mob.State != MobStateReturning) {
+		mob.Path = nil
+		return targetX, targetY
+	}
+	if grid.lineOfSight(mob.X, mob.Y, targetX, targetY) {
+		mob.Path = nil
+		return targetX, targetY
+	}
+
+	// Перестраиваем маршрут, если цель сместилась в другую клетку или маршрут кончился
+	goal := grid.cellAt(targetX, targetY)
+	if len(mob.Path) == 0 || goal != grid.cellAt(mob.PathGoal.X, mob.PathGoal.Y) {
+		if now.Sub(mob.PathPlanned) >= NavReplanDelay || len(mob.Path) == 0 {
+			mob.Path = g.pathLocked(mob.Zone, grid, mob.X, mob.Y, targetX, targetY, now)
+			mob.PathIndex = 0
+			mob.PathGoal = Point{targetX, targetY}
+			mob.PathPlanned = now
+		}
+	}
+
+	for mob.PathIndex < len(mob.Path) {
+		waypoint := mob.Path[mob.PathIndex]
+		if mob.DistanceTo(waypoint.X, waypoint.Y) > NavWaypointReach {
+			return waypoint.X, waypoint.Y
+		}
+		mob.PathIndex++
+	}
+	// Маршрут пройден или не найден — идём напрямую, препятствия вытолкнут
+	return targetX, targetY
+}
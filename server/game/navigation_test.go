@@ -0,0 +1,158 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// testGrid строит сетку из строк: '#' — занятая клетка, '.' — свободная
+func testGrid(rows ...string) *navGrid {
+	grid := &navGrid{Cols: len(rows[0]), Rows: len(rows)}
+	grid.Blocked = make([]bool, grid.Cols*grid.Rows)
+	for y, row := range rows {
+		for x, ch := range row {
+			grid.Blocked[y*grid.Cols+x] = ch == '#'
+		}
+	}
+	return grid
+}
+
+func TestNavCanStepCornerCutting(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   []string
+		dx, dy int
+		want   bool
+	}{
+		{"open diagonal", []string{"..", ".."}, 1, 1, true},
+		{"straight past a wall", []string{".#", ".."}, 0, 1, true},
+		{"diagonal past horizontal neighbour", []string{".#", ".."}, 1, 1, false},
+		{"diagonal past vertical neighbour", []string{"..", "#."}, 1, 1, false},
+		{"diagonal between two walls", []string{".#", "#."}, 1, 1, false},
+		{"blocked target", []string{"..", ".#"}, 1, 1, false},
+		{"outside the grid", []string{"..", ".."}, -1, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testGrid(tt.rows...).canStep(navCell{0, 0}, tt.dx, tt.dy); got != tt.want {
+				t.Errorf("canStep(%d, %d) = %v, want %v", tt.dx, tt.dy, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNavFindPathNoCornerCutting(t *testing.T) {
+	// Между (0,0) и (1,1) только диагональ, зажатая стенами — пути нет
+	grid := testGrid(
+		".#.",
+		"#..",
+		"...",
+	)
+	sx, sy := grid.center(navCell{0, 0})
+	gx, gy := grid.center(navCell{1, 1})
+	if path := grid.findPath(sx, sy, gx, gy); path != nil {
+		t.Errorf("found path %v through a pinched corner", path)
+	}
+}
+
+func TestNavNearestOpen(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   []string
+		cell   navCell
+		want   navCell
+		wantOK bool
+	}{
+		{"open cell", []string{"...", "...", "..."}, navCell{1, 1}, navCell{1, 1}, true},
+		{"blocked center", []string{"...", ".#.", "..."}, navCell{1, 1}, navCell{0, 0}, true},
+		{"two rings out", []string{
+			"#####",
+			"#####",
+			"#####",
+			"#####",
+			"####.",
+		}, navCell{2, 2}, navCell{4, 4}, true},
+		{"everything blocked", []string{"##", "##"}, navCell{0, 0}, navCell{0, 0}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grid := testGrid(tt.rows...)
+			got, ok := grid.nearestOpen(tt.cell)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("nearestOpen(%v) = %v, %v; want %v, %v", tt.cell, got, ok, tt.want, tt.wantOK)
+			}
+			if ok && grid.blocked(got) {
+				t.Errorf("nearestOpen returned blocked cell %v", got)
+			}
+		})
+	}
+}
+
+func TestNavFindPathToBlockedGoal(t *testing.T) {
+	grid := testGrid(
+		".....",
+		".....",
+		"..#..",
+		".....",
+	)
+	sx, sy := grid.center(navCell{0, 0})
+	gx, gy := grid.center(navCell{2, 2})
+	path := grid.findPath(sx, sy, gx, gy)
+	if len(path) == 0 {
+		t.Fatal("no path to a cell next to the blocked goal")
+	}
+	end := path[len(path)-1]
+	if grid.blocked(grid.cellAt(end.X, end.Y)) {
+		t.Errorf("path ends inside the obstacle at %v", end)
+	}
+	if math.Abs(end.X-gx) > NavCellSize*1.5 || math.Abs(end.Y-gy) > NavCellSize*1.5 {
+		t.Errorf("path ends at %v, too far from goal (%v, %v)", end, gx, gy)
+	}
+}
+
+func TestNavSmooth(t *testing.T) {
+	open := testGrid(
+		".....",
+		".....",
+		".....",
+	)
+	var line []Point
+	for x := 0; x < 5; x++ {
+		cx, cy := open.center(navCell{x, 1})
+		line = append(line, Point{cx, cy})
+	}
+	if got := open.smooth(line); len(got) != 2 || got[0] != line[0] || got[1] != line[4] {
+		t.Errorf("straight line smoothed to %v, want just its ends", got)
+	}
+
+	// Обход стены: угол у её конца должен остаться
+	walled := testGrid(
+		".#...",
+		".#...",
+		".#...",
+		".....",
+	)
+	cells := []navCell{{0, 0}, {0, 1}, {0, 2}, {0, 3}, {1, 3}, {2, 3}, {2, 2}, {3, 1}, {4, 0}}
+	var path []Point
+	for _, c := range cells {
+		x, y := walled.center(c)
+		path = append(path, Point{x, y})
+	}
+	got := walled.smooth(path)
+	if got[0] != path[0] || got[len(got)-1] != path[len(path)-1] {
+		t.Errorf("smooth changed the endpoints: %v", got)
+	}
+	if len(got) >= len(path) || len(got) < 3 {
+		t.Errorf("smoothed %d points to %d, want fewer but still a turn", len(path), len(got))
+	}
+	for i := 1; i < len(got); i++ {
+		if !walled.lineOfSight(got[i-1].X, got[i-1].Y, got[i].X, got[i].Y) {
+			t.Errorf("no line of sight between %v and %v", got[i-1], got[i])
+		}
+	}
+
+	short := []Point{{1, 1}, {2, 2}}
+	if got := walled.smooth(short); len(got) != 2 {
+		t.Errorf("two-point path changed to %v", got)
+	}
+}
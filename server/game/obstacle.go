@@ -0,0 +1,220 @@
+package game
+
+import (
+	"fmt"
+	"math"
+)
+
+// ObstacleShape — форма препятствия
+type ObstacleShape string
+
+const (
+	ObstacleCircle  ObstacleShape = "circle"
+	ObstaclePolygon ObstacleShape = "polygon"
+)
+
+// ObstacleKind — что это за препятствие и кого оно останавливает
+type ObstacleKind string
+
+const (
+	ObstacleRock  ObstacleKind = "rock"
+	ObstacleWall  ObstacleKind = "wall"
+	ObstacleWater ObstacleKind = "water"
+)
+
+// ObstacleKinds — кого блокирует препятствие каждого вида.
+// Вода останавливает игроков и мобов, но лепестки и снаряды летят над ней.
+var ObstacleKinds = map[ObstacleKind]struct {
+	BlocksPetals      bool
+	BlocksProjectiles bool
+}{
+	ObstacleRock:  {BlocksPetals: true, BlocksProjectiles: true},
+	ObstacleWall:  {BlocksPetals: true, BlocksProjectiles: true},
+	ObstacleWater: {},
+}
+
+// Point — вершина многоугольника
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Obstacle — статическое препятствие внутри зоны
+type Obstacle struct {
+	ID     string        `json:"id"`
+	Kind   ObstacleKind  `json:"kind"`
+	Shape  ObstacleShape `json:"shape"`
+	X      float64       `json:"x,omitempty"` // центр круга
+	Y      float64       `json:"y,omitempty"`
+	Radius float64       `json:"radius,omitempty"`
+	Points []Point       `json:"points,omitempty"` // вершины многоугольника по порядку
+}
+
+// validate проверяет препятствие из файла карты
+func (o *Obstacle) validate() error {
+	if _, ok := ObstacleKinds[o.Kind]; !ok {
+		return fmt.Errorf("obstacle %q: unknown kind %q", o.ID, o.Kind)
+	}
+	switch o.Shape {
+	case ObstacleCircle:
+		if o.Radius <= 0 {
+			return fmt.Errorf("obstacle %q: circle needs positive radius", o.ID)
+		}
+	case ObstaclePolygon:
+		if len(o.Points) < 3 {
+			return fmt.Errorf("obstacle %q: polygon needs at least 3 points", o.ID)
+		}
+	default:
+		return fmt.Errorf("obstacle %q: unknown shape %q", o.ID, o.Shape)
+	}
+	return nil
+}
+
+// PushOut выталкивает круг (x, y, r) из препятствия.
+// Возвращает новую позицию и true, если было пересечение.
+func (o *Obstacle) PushOut(x, y, r float64) (float64, float64, bool) {
+	switch o.Shape {
+	case ObstacleCircle:
+		dx, dy := x-o.X, y-o.Y
+		dist := math.Sqrt(dx*dx + dy*dy)
+		minDist := o.Radius + r
+		if dist >= minDist {
+			return x, y, false
+		}
+		if dist == 0 {
+			return o.X + minDist, y, true
+		}
+		return o.X + dx/dist*minDist, o.Y + dy/dist*minDist, true
+
+	case ObstaclePolygon:
+		cx, cy := o.closestEdgePoint(x, y)
+		dx, dy := x-cx, y-cy
+		dist := math.Sqrt(dx*dx + dy*dy)
+		inside := o.contains(x, y)
+		if !inside && dist >= r {
+			return x, y, false
+		}
+		if dist == 0 {
+			return x, y, true // ровно на ребре — оставляем, следующий тик сдвинет
+		}
+		if inside {
+			// Изнутри — наружу через ближайшее ребро
+			return cx - dx/dist*r, cy - dy/dist*r, true
+		}
+		return cx + dx/dist*r, cy + dy/dist*r, true
+	}
+	return x, y, false
+}
+
+// Overlaps — пересекается ли круг с препятствием
+func (o *Obstacle) Overlaps(x, y, r float64) bool {
+	_, _, hit := o.PushOut(x, y, r)
+	return hit
+}
+
+// contains — точка внутри многоугольника (чётность пересечений луча)
+func (o *Obstacle) contains(x, y float64) bool {
+	inside := false
+	n := len(o.Points)
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a, b := o.Points[i], o.Points[j]
+		if (a.Y > y) != (b.Y > y) && x < (b.X-a.X)*(y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// closestEdgePoint — ближайшая к (x, y) точка на границе многоугольника
+func (o *Obstacle) closestEdgePoint(x, y float64) (float64, float64) {
+	bestX, bestY := o.Points[0].X, o.Points[0].Y
+	best := math.MaxFloat64
+	n := len(o.Points)
+	for i := 0; i < n; i++ {
+		a, b := o.Points[i], o.Points[(i+1)%n]
+		ex, ey := b.X-a.X, b.Y-a.Y
+		t := 0.0
+		if lenSq := ex*ex + ey*ey; lenSq > 0 {
+			t = math.Max(0, math.Min(1, ((x-a.X)*ex+(y-a.Y)*ey)/lenSq))
+		}
+		px, py := a.X+ex*t, a.Y+ey*t
+		if d := (x-px)*(x-px) + (y-py)*(y-py); d < best {
+			best = d
+			bestX, bestY = px, py
+		}
+	}
+	return bestX, bestY
+}
+
+// resolveObstaclesLocked — выталкивает круг из всех препятствий зоны.
+// petals — учитывать только препятствия, которые блокируют лепестки.
+func (g *Game) resolveObstaclesLocked(zoneName string, x, y, r float64, petals bool) (float64, float64) {
+	zone := g.zones[zoneName]
+	if zone == nil {
+		return x, y
+	}
+	// Несколько проходов на случай стыков соседних препятствий
+	for pass := 0; pass < 3; pass++ {
+		moved := false
+		for _, o := range zone.Obstacles {
+			if petals && !ObstacleKinds[o.Kind].BlocksPetals {
+				continue
+			}
+			var hit bool
+			if x, y, hit = o.PushOut(x, y, r); hit {
+				moved = true
+			}
+		}
+		if !moved {
+			break
+		}
+	}
+	return x, y
+}
+
+// blockedLocked — пересекается ли круг с каким-либо препятствием зоны
+func (g *Game) blockedLocked(zoneName string, x, y, r float64) bool {
+	zone := g.zones[zoneName]
+	if zone == nil {
+		return false
+	}
+	for _, o := range zone.Obstacles {
+		if o.Overlaps(x, y, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// projectileBlockedLocked — упёрся ли снаряд в препятствие
+func (g *Game) projectileBlockedLocked(projectile *Projectile) bool {
+	zone := g.zones[projectile.Zone]
+	if zone == nil {
+		return false
+	}
+	for _, o := range zone.Obstacles {
+		if ObstacleKinds[o.Kind].BlocksProjectiles && o.Overlaps(projectile.X, projectile.Y, projectile.Radius) {
+			return true
+		}
+	}
+	return false
+}
+
+// sendZoneObstaclesLocked — геометрия зоны игроку (при входе в зону)
+func (g *Game) sendZoneObstaclesLocked(player *Player) {
+	conn, ok := g.connections[player.ID]
+	if !ok {
+		return
+	}
+	var obstacles []*Obstacle
+	if zone := g.zones[player.CurrentZone]; zone != nil {
+		obstacles = zone.Obstacles
+	}
+	conn.WriteJSON(map[string]interface{}{
+		"type": "zone_obstacles",
+		"data": map[string]interface{}{
+			"zone":      player.CurrentZone,
+			"obstacles": obstacles,
+		},
+	})
+}
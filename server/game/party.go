@@ -0,0 +1,133 @@
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+// PartyInviteTimeout — сколько действует приглашение в группу
+const PartyInviteTimeout = 60 * time.Second
+
+// partyInvite — приглашение, ожидающее ответа игрока
+type partyInvite struct {
+	PartyID string
+	From    string
+	Expires time.Time
+}
+
+// InvitePlayerToParty — приглашает игрока в группу пригласившего.
+// Если у пригласившего ещё нет группы, сервер создаёт её. ID групп выдаёт
+// только сервер, поэтому войти в чужую группу можно лишь по приглашению.
+func (g *Game) InvitePlayerToParty(playerID, targetID string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	player := g.players[playerID]
+	target := g.players[targetID]
+	if player == nil || target == nil || playerID == targetID {
+		return
+	}
+	if player.PartyID != "" && target.PartyID == player.PartyID {
+		return
+	}
+
+	if player.PartyID == "" {
+		g.entitySeq++
+		player.PartyID = fmt.Sprintf("party_%d", g.entitySeq)
+		g.sendPartyChangedLocked(player.PartyID, "")
+	}
+	g.partyInvites[targetID] = &partyInvite{
+		PartyID: player.PartyID,
+		From:    playerID,
+		Expires: time.Now().Add(PartyInviteTimeout),
+	}
+
+	if conn, ok := g.connections[targetID]; ok {
+		conn.WriteJSON(map[string]interface{}{
+			"type": "party_invite",
+			"data": map[string]interface{}{
+				"party_id": player.PartyID,
+				"from":     playerID,
+				"username": player.Username,
+			},
+		})
+	}
+}
+
+// AcceptPartyInvite — принимает приглашение в группу partyID
+func (g *Game) AcceptPartyInvite(playerID, partyID string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	player := g.players[playerID]
+	invite := g.partyInvites[playerID]
+	if player == nil || invite == nil || invite.PartyID != partyID {
+		return
+	}
+	delete(g.partyInvites, playerID)
+	if time.Now().After(invite.Expires) || len(g.partyMembersLocked(partyID)) == 0 {
+		return // приглашение устарело или группа уже распалась
+	}
+
+	previous := player.PartyID
+	player.PartyID = partyID
+	if previous != "" {
+		g.sendPartyChangedLocked(previous, "")
+	}
+	g.sendPartyChangedLocked(partyID, "")
+}
+
+// LeaveParty — выход из группы
+func (g *Game) LeaveParty(playerID string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if player := g.players[playerID]; player != nil {
+		g.leavePartyLocked(player)
+	}
+}
+
+// leavePartyLocked — убирает игрока из группы и оповещает оставшихся
+func (g *Game) leavePartyLocked(player *Player) {
+	partyID := player.PartyID
+	if partyID == "" {
+		return
+	}
+	player.PartyID = ""
+	g.sendPartyChangedLocked(partyID, player.ID)
+}
+
+// partyMembersLocked — ID игроков группы
+func (g *Game) partyMembersLocked(partyID string) []string {
+	var members []string
+	for id, player := range g.players {
+		if player.PartyID == partyID {
+			members = append(members, id)
+		}
+	}
+	return members
+}
+
+// sendPartyChangedLocked — рассылает состав группы её членам
+// (и игроку extraID, например только что вышедшему)
+func (g *Game) sendPartyChangedLocked(partyID, extraID string) {
+	members := g.partyMembersLocked(partyID)
+	recipients := members
+	if extraID != "" && g.players[extraID] != nil && g.players[extraID].PartyID != partyID {
+		recipients = append(recipients[:len(recipients):len(recipients)], extraID)
+	}
+	for _, id := range recipients {
+		conn, ok := g.connections[id]
+		if !ok {
+			continue
+		}
+		data := map[string]interface{}{"party_id": partyID, "members": members}
+		if g.players[id].PartyID != partyID {
+			data = map[string]interface{}{"party_id": ""} // игрок вышел из группы
+		}
+		conn.WriteJSON(map[string]interface{}{
+			"type": "party_changed",
+			"data": data,
+		})
+	}
+}
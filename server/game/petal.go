@@ -12,25 +12,38 @@ const (
 	PetalTypeWolf   PetalType = "wolf"
 	PetalTypeGoblin PetalType = "goblin"
 	PetalTypeOrc    PetalType = "orc"
+	PetalTypeSpear  PetalType = "spear"  // стреляет снарядами
+	PetalTypeShell  PetalType = "shell"  // щит
+	PetalTypeEgg    PetalType = "egg"    // призывает союзника
+	PetalTypePollen PetalType = "pollen" // урон по области
 )
 
 type Petal struct {
-	ID         string    `json:"id"`
-	Type       PetalType `json:"type"`
-	Health     int       `json:"health"`
-	MaxHealth  int       `json:"max_health"`
-	Damage     int       `json:"damage"`
-	HealAmount int       `json:"heal_amount"`
-	HealRate   float64   `json:"heal_rate"` // seconds between heals
-	Radius     float64   `json:"radius"`    // orbit radius
-	Angle      float64   `json:"angle"`     // current orbit angle
-	Speed      float64   `json:"speed"`     // orbit speed
-	OwnerID    string    `json:"owner_id"`
-	IsActive   bool      `json:"is_active"`
-	LastHeal   time.Time `json:"-"`
-	LastAttack time.Time `json:"-"`
-	X          float64   `json:"x"` // current x position
-	Y          float64   `json:"y"`
+	ID          string        `json:"id"`
+	Type        PetalType     `json:"type"`
+	Rarity      Rarity        `json:"rarity"`
+	Health      int           `json:"health"`
+	MaxHealth   int           `json:"max_health"`
+	Damage      int           `json:"damage"`
+	HealAmount  int           `json:"heal_amount"`
+	HealRate    float64       `json:"heal_rate"`    // seconds between heals
+	Radius      float64       `json:"radius"`       // orbit radius
+	OrbitRadius float64       `json:"orbit_radius"` // current (interpolated) orbit radius
+	Angle       float64       `json:"angle"`        // current orbit angle
+	Speed       float64       `json:"speed"`        // orbit angular speed (shared by the formation ring)
+	SlotAngle   float64       `json:"slot_angle"`   // offset inside the owner's formation
+	OwnerID     string        `json:"owner_id"`
+	IsActive    bool          `json:"is_active"`
+	LastHeal    time.Time     `json:"-"`
+	LastAttack  time.Time     `json:"-"`
+	LastTrigger time.Time     `json:"-"` // последнее срабатывание поведения (выстрел, призыв, импульс)
+	Effects     StatusEffects `json:"effects"`
+	// Перезарядка после уничтожения
+	ReloadTime     time.Duration `json:"-"`
+	ReloadStarted  time.Time     `json:"-"`
+	ReloadProgress float64       `json:"reload_progress"` // 0..1, заполняется при сериализации
+	X              float64       `json:"x"`               // current x position
+	Y              float64       `json:"y"`
 }
 
 // Конфигурация лепестков
@@ -40,7 +53,16 @@ var PetalConfigs = map[PetalType]struct {
 	HealAmount int
 	HealRate   float64
 	Radius     float64
-	Speed      float64
+	// ClusterSize > 1 — столько лепестков этого типа занимают один слот формации
+	ClusterSize int
+	// ReloadTimes — время восстановления после уничтожения по редкостям (секунды).
+	// Если редкости нет в карте, берётся значение для RarityCommon.
+	ReloadTimes map[Rarity]float64
+	// Behavior — имя зарегистрированного поведения (см. petal_behavior.go)
+	Behavior string
+	Params   PetalBehaviorParams
+	// OnHit — эффекты, которые лепесток накладывает на моба при ударе
+	OnHit []EffectSpec
 }{
 	PetalTypeWolf: {
 		Health:     50,
@@ -48,15 +70,22 @@ var PetalConfigs = map[PetalType]struct {
 		HealAmount: 5,
 		HealRate:   2.0, // heal every 2 seconds
 		Radius:     60,
-		Speed:      1.5,
+		ReloadTimes: map[Rarity]float64{
+			RarityCommon: 4.0, RarityRare: 3.5, RarityLegendary: 2.5,
+		},
+		Behavior: PetalBehaviorHeal,
 	},
 	PetalTypeGoblin: {
-		Health:     15,
-		Damage:     20,
-		HealAmount: 0,
-		HealRate:   0,
-		Radius:     50,
-		Speed:      2.0,
+		Health:      15,
+		Damage:      20,
+		HealAmount:  0,
+		HealRate:    0,
+		Radius:      50,
+		ClusterSize: 3, // гоблины держатся кучкой
+		ReloadTimes: map[Rarity]float64{
+			RarityCommon: 1.5, RarityEpic: 1.2, RarityLegendary: 1.0,
+		},
+		Behavior: PetalBehaviorOrbit,
 	},
 	PetalTypeOrc: {
 		Health:     20,
@@ -64,39 +93,117 @@ var PetalConfigs = map[PetalType]struct {
 		HealAmount: 0,
 		HealRate:   0,
 		Radius:     70,
-		Speed:      1.2,
+		ReloadTimes: map[Rarity]float64{
+			RarityCommon: 2.5, RarityRare: 2.2, RarityLegendary: 1.8,
+		},
+		Behavior: PetalBehaviorOrbit,
+		OnHit: []EffectSpec{
+			{Type: EffectSlow, Duration: 1.5, Magnitude: 0.3, Stacking: StackRefresh, Chance: 0.35},
+		},
+	},
+	PetalTypeSpear: {
+		Health:      10,
+		Radius:      55,
+		ReloadTimes: map[Rarity]float64{RarityCommon: 3.0},
+		Behavior:    PetalBehaviorProjectile,
+		Params:      PetalBehaviorParams{Cooldown: 1.2, Range: 450, Power: 14, ProjectileSpeed: 45},
+	},
+	PetalTypeShell: {
+		Health:      60,
+		Radius:      40,
+		ReloadTimes: map[Rarity]float64{RarityCommon: 6.0, RarityLegendary: 4.0},
+		Behavior:    PetalBehaviorShield,
+		Params:      PetalBehaviorParams{ShieldShare: 0.5},
 	},
+	PetalTypeEgg: {
+		Health:      25,
+		Radius:      45,
+		ReloadTimes: map[Rarity]float64{RarityCommon: 5.0},
+		Behavior:    PetalBehaviorSummon,
+		Params:      PetalBehaviorParams{Cooldown: 8, Power: 6, MinionDuration: 6},
+	},
+	PetalTypePollen: {
+		Health:      20,
+		Radius:      65,
+		ReloadTimes: map[Rarity]float64{RarityCommon: 3.0},
+		Behavior:    PetalBehaviorArea,
+		Params:      PetalBehaviorParams{Cooldown: 2.5, Range: 80, Power: 8},
+		OnHit: []EffectSpec{
+			{Type: EffectPoison, Duration: 4, Magnitude: 3, Stacking: StackAdd, MaxStacks: 3},
+		},
+	},
+}
+
+// Множители характеристик лепестков для редкостей
+var PetalRarityMultipliers = map[Rarity]float64{
+	RarityCommon:    1.0,
+	RarityUncommon:  1.5,
+	RarityRare:      2.25,
+	RarityEpic:      3.4,
+	RarityLegendary: 5.0,
+}
+
+// DefaultPetalReloadTime — время восстановления, если в конфиге ничего не задано
+const DefaultPetalReloadTime = 2 * time.Second
+
+// petalReloadTime — время восстановления лепестка по типу и редкости
+func petalReloadTime(petalType PetalType, rarity Rarity) time.Duration {
+	times := PetalConfigs[petalType].ReloadTimes
+	seconds, ok := times[rarity]
+	if !ok {
+		seconds, ok = times[RarityCommon]
+	}
+	if !ok || seconds <= 0 {
+		return DefaultPetalReloadTime
+	}
+	return time.Duration(seconds * float64(time.Second))
 }
 
-func NewPetal(petalType PetalType, ownerID string) *Petal {
+func NewPetal(petalType PetalType, rarity Rarity, ownerID string) *Petal {
 	config := PetalConfigs[petalType]
+	multiplier, ok := PetalRarityMultipliers[rarity]
+	if !ok {
+		rarity = RarityCommon
+		multiplier = 1.0
+	}
+
+	health := int(float64(config.Health) * multiplier)
 
 	return &Petal{
-		ID:         fmt.Sprintf("petal_%s_%d", petalType, time.Now().UnixNano()),
-		Type:       petalType,
-		Health:     config.Health,
-		MaxHealth:  config.Health,
-		Damage:     config.Damage,
-		HealAmount: config.HealAmount,
-		HealRate:   config.HealRate,
-		Radius:     config.Radius,
-		Angle:      0,
-		Speed:      config.Speed,
-		OwnerID:    ownerID,
-		IsActive:   true,
-		LastHeal:   time.Now(),
-		LastAttack: time.Now(),
+		ID:          fmt.Sprintf("petal_%s_%d", petalType, time.Now().UnixNano()),
+		Type:        petalType,
+		Rarity:      rarity,
+		Health:      health,
+		MaxHealth:   health,
+		Damage:      int(float64(config.Damage) * multiplier),
+		HealAmount:  int(float64(config.HealAmount) * multiplier),
+		HealRate:    config.HealRate,
+		Radius:      config.Radius,
+		OrbitRadius: config.Radius,
+		Speed:       FormationRotationSpeed, // все лепестки вращаются вместе с кольцом
+		OwnerID:     ownerID,
+		IsActive:    true,
+		LastHeal:    time.Now(),
+		LastAttack:  time.Now(),
+		ReloadTime:  petalReloadTime(petalType, rarity),
 	}
 }
 
-func (p *Petal) UpdatePosition(playerX, playerY float64, deltaTime float64) (float64, float64) {
-	p.Angle += p.Speed * deltaTime
-	if p.Angle > 2*math.Pi {
-		p.Angle -= 2 * math.Pi
+// UpdatePosition ставит лепесток на орбиту: угол = поворот кольца формации
+// владельца + SlotAngle. radiusMultiplier задаётся стойкой владельца:
+// текущий радиус плавно стремится к Radius*radiusMultiplier.
+func (p *Petal) UpdatePosition(playerX, playerY, formationAngle, radiusMultiplier, deltaTime float64) (float64, float64) {
+	p.Angle = math.Mod(formationAngle+p.SlotAngle, 2*math.Pi)
+
+	targetRadius := p.Radius * radiusMultiplier
+	t := StanceRadiusLerpSpeed * deltaTime
+	if t > 1 {
+		t = 1
 	}
+	p.OrbitRadius += (targetRadius - p.OrbitRadius) * t
 
-	x := playerX + p.Radius*math.Cos(p.Angle)
-	y := playerY + p.Radius*math.Sin(p.Angle)
+	x := playerX + p.OrbitRadius*math.Cos(p.Angle)
+	y := playerY + p.OrbitRadius*math.Sin(p.Angle)
 
 	return x, y
 }
@@ -106,11 +213,11 @@ func (p *Petal) CanHeal() bool {
 }
 
 func (p *Petal) CanAttack() bool {
-	return p.IsActive && p.Damage > 0 && time.Since(p.LastAttack) >= 500*time.Millisecond
+	return p.IsActive && p.Damage > 0 && !p.Effects.IsStunned() && time.Since(p.LastAttack) >= 500*time.Millisecond
 }
 
 func (p *Petal) TakeDamage(damage int) {
-	p.Health -= damage
+	p.Health -= scaleDamage(damage, p.Effects.IncomingDamageMultiplier())
 	if p.Health <= 0 {
 		p.Health = 0
 		p.IsActive = false
@@ -121,7 +228,36 @@ func (p *Petal) IsAlive() bool {
 	return p.Health > 0
 }
 
+// StartReload запускает таймер восстановления уничтоженного лепестка
+func (p *Petal) StartReload(now time.Time) {
+	p.ReloadStarted = now
+}
+
+// IsReloading — лепесток уничтожен и ждёт восстановления
+func (p *Petal) IsReloading() bool {
+	return !p.IsActive && !p.ReloadStarted.IsZero()
+}
+
+// ReloadDone — истёк ли таймер восстановления
+func (p *Petal) ReloadDone(now time.Time) bool {
+	return p.IsReloading() && now.Sub(p.ReloadStarted) >= p.ReloadTime
+}
+
+// GetReloadProgress — прогресс восстановления от 0 до 1 (1 — активен)
+func (p *Petal) GetReloadProgress(now time.Time) float64 {
+	if !p.IsReloading() || p.ReloadTime <= 0 {
+		return 1
+	}
+	progress := float64(now.Sub(p.ReloadStarted)) / float64(p.ReloadTime)
+	if progress > 1 {
+		progress = 1
+	}
+	return progress
+}
+
 func (p *Petal) Respawn() {
 	p.Health = p.MaxHealth
 	p.IsActive = true
+	p.ReloadStarted = time.Time{}
+	p.Effects = nil
 }
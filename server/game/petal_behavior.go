@@ -0,0 +1,257 @@
+package game
+
+import (
+	"math"
+	"time"
+)
+
+// Имена поведений лепестков (PetalConfigs[...].Behavior)
+const (
+	PetalBehaviorOrbit      = "orbit"      // урон только касанием на орбите
+	PetalBehaviorHeal       = "heal"       // периодически лечит владельца
+	PetalBehaviorProjectile = "projectile" // стреляет в ближайшего моба
+	PetalBehaviorShield     = "shield"     // поглощает часть входящего урона
+	PetalBehaviorSummon     = "summon"     // призывает временного союзника
+	PetalBehaviorArea       = "area"       // периодически бьёт по области вокруг себя
+)
+
+// PetalBehaviorParams — параметры архетипа из конфига лепестка
+type PetalBehaviorParams struct {
+	Cooldown        float64 // секунд между срабатываниями
+	Range           float64 // дальность поиска цели / радиус области
+	Power           int     // урон снаряда, союзника или области (масштабируется редкостью)
+	ProjectileSpeed float64 // скорость снаряда (единиц за тик)
+	ShieldShare     float64 // доля входящего урона, которую забирает щит (0..1)
+	MinionDuration  float64 // время жизни союзника в секундах
+}
+
+// PetalBehavior — подключаемое поведение лепестка. Update вызывается
+// каждый тик для активных лепестков под g.mu.
+type PetalBehavior interface {
+	Update(g *Game, player *Player, petal *Petal, now time.Time)
+}
+
+// PetalDamageAbsorber — поведение, которое может перехватить урон владельцу
+type PetalDamageAbsorber interface {
+	AbsorbDamage(player *Player, petal *Petal, damage int) int
+}
+
+var petalBehaviors = map[string]PetalBehavior{}
+
+// RegisterPetalBehavior регистрирует поведение под именем из конфига
+func RegisterPetalBehavior(name string, behavior PetalBehavior) {
+	petalBehaviors[name] = behavior
+}
+
+func init() {
+	RegisterPetalBehavior(PetalBehaviorOrbit, orbitBehavior{})
+	RegisterPetalBehavior(PetalBehaviorHeal, healBehavior{})
+	RegisterPetalBehavior(PetalBehaviorProjectile, projectileBehavior{})
+	RegisterPetalBehavior(PetalBehaviorShield, shieldBehavior{})
+	RegisterPetalBehavior(PetalBehaviorSummon, summonBehavior{})
+	RegisterPetalBehavior(PetalBehaviorArea, areaBehavior{})
+}
+
+// behaviorFor — поведение лепестка по его типу (по умолчанию — орбита)
+func behaviorFor(petal *Petal) (PetalBehavior, PetalBehaviorParams) {
+	config := PetalConfigs[petal.Type]
+	behavior, ok := petalBehaviors[config.Behavior]
+	if !ok {
+		behavior = petalBehaviors[PetalBehaviorOrbit]
+	}
+	return behavior, config.Params
+}
+
+// ready — прошёл ли кулдаун архетипа (и отмечает срабатывание)
+func ready(petal *Petal, params PetalBehaviorParams, now time.Time) bool {
+	if now.Sub(petal.LastTrigger) < time.Duration(params.Cooldown*float64(time.Second)) {
+		return false
+	}
+	petal.LastTrigger = now
+	return true
+}
+
+// power — сила эффекта с учётом редкости лепестка и стойки владельца
+func power(player *Player, petal *Petal, params PetalBehaviorParams) int {
+	multiplier, ok := PetalRarityMultipliers[petal.Rarity]
+	if !ok {
+		multiplier = 1.0
+	}
+	return scaleDamage(params.Power, multiplier*player.StanceDamageMultiplier())
+}
+
+type orbitBehavior struct{}
+
+func (orbitBehavior) Update(g *Game, player *Player, petal *Petal, now time.Time) {}
+
+type healBehavior struct{}
+
+func (healBehavior) Update(g *Game, player *Player, petal *Petal, now time.Time) {
+	if !petal.CanHeal() || player.Health >= player.MaxHealth {
+		return
+	}
+
+	// Исцеляем игрока
+	player.Health += petal.HealAmount
+	if player.Health > player.MaxHealth {
+		player.Health = player.MaxHealth
+	}
+	petal.LastHeal = now
+	g.addHealingThreatLocked(player, petal.HealAmount)
+
+	// Отправляем уведомление об исцелении
+	if conn, ok := g.connections[player.ID]; ok {
+		conn.WriteJSON(map[string]interface{}{
+			"type": "petal_healed",
+			"data": map[string]interface{}{
+				"petal_id": petal.ID,
+				"amount":   petal.HealAmount,
+				"health":   player.Health,
+			},
+		})
+	}
+}
+
+type projectileBehavior struct{}
+
+func (projectileBehavior) Update(g *Game, player *Player, petal *Petal, now time.Time) {
+	_, params := behaviorFor(petal)
+	target, distance := g.findClosestMobInZoneLocked(petal.X, petal.Y, player.CurrentZone)
+	if target == nil || distance > params.Range || !ready(petal, params, now) {
+		return
+	}
+
+	angle := math.Atan2(target.Y-petal.Y, target.X-petal.X)
+	projectile := g.spawnProjectile(player, petal.X, petal.Y, angle, params.ProjectileSpeed, power(player, petal, params), params.Range)
+	if projectile != nil {
+		projectile.OnHit = PetalConfigs[petal.Type].OnHit
+	}
+}
+
+type shieldBehavior struct{}
+
+func (shieldBehavior) Update(g *Game, player *Player, petal *Petal, now time.Time) {}
+
+// AbsorbDamage забирает ShieldShare урона в здоровье лепестка-щита
+func (shieldBehavior) AbsorbDamage(player *Player, petal *Petal, damage int) int {
+	_, params := behaviorFor(petal)
+	absorbed := int(float64(damage) * params.ShieldShare)
+	if absorbed > petal.Health {
+		absorbed = petal.Health
+	}
+	petal.TakeDamage(absorbed)
+	return damage - absorbed
+}
+
+type summonBehavior struct{}
+
+func (summonBehavior) Update(g *Game, player *Player, petal *Petal, now time.Time) {
+	_, params := behaviorFor(petal)
+	if !ready(petal, params, now) {
+		return
+	}
+
+	lifetime := time.Duration(params.MinionDuration * float64(time.Second))
+	g.spawnMinion(player, petal.X, petal.Y, power(player, petal, params), lifetime)
+}
+
+type areaBehavior struct{}
+
+func (areaBehavior) Update(g *Game, player *Player, petal *Petal, now time.Time) {
+	_, params := behaviorFor(petal)
+	if !ready(petal, params, now) {
+		return
+	}
+
+	damage := power(player, petal, params)
+	for _, mob := range g.mobs {
+		if !mob.IsAlive() || mob.Zone != player.CurrentZone {
+			continue
+		}
+		if mob.DistanceTo(petal.X, petal.Y) <= params.Range+mob.Radius {
+			mob.Effects.ApplyAll(PetalConfigs[petal.Type].OnHit, player.ID, now)
+			g.damageMobByPlayer(player, mob, damage)
+		}
+	}
+
+	if conn, ok := g.connections[player.ID]; ok {
+		conn.WriteJSON(map[string]interface{}{
+			"type": "petal_pulse",
+			"data": map[string]interface{}{
+				"petal_id": petal.ID,
+				"x":        petal.X,
+				"y":        petal.Y,
+				"radius":   params.Range,
+			},
+		})
+	}
+}
+
+// updatePetalBehaviors — тик поведений лепестков и их союзных сущностей
+func (g *Game) updatePetalBehaviors() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+
+	for _, player := range g.players {
+		if !player.IsAlive() {
+			continue
+		}
+		for _, petal := range player.GetActivePetals() {
+			behavior, _ := behaviorFor(petal)
+			behavior.Update(g, player, petal, now)
+		}
+	}
+
+	g.updateProjectilesLocked(now)
+	g.updateMinionsLocked(now)
+}
+
+// absorbPlayerDamage — пропускает урон игроку через лепестки-щиты
+func (g *Game) absorbPlayerDamage(player *Player, damage int) int {
+	for _, petal := range player.GetActivePetals() {
+		if damage <= 0 {
+			break
+		}
+		behavior, _ := behaviorFor(petal)
+		absorber, ok := behavior.(PetalDamageAbsorber)
+		if !ok {
+			continue
+		}
+		damage = absorber.AbsorbDamage(player, petal, damage)
+		if !petal.IsActive {
+			g.handlePetalDestroyed(petal)
+		}
+	}
+	return damage
+}
+
+// damageMobByPlayer — наносит урон мобу от имени игрока и обрабатывает
+// убийство. Возвращает true, если моб умер от этого удара.
+func (g *Game) damageMobByPlayer(player *Player, mob *Mob, damage int) bool {
+	return g.damageMobLocked(mob, damage, player)
+}
+
+// findClosestMobInZoneLocked — ближайший живой моб в зоне
+func (g *Game) findClosestMobInZoneLocked(x, y float64, zone string) (*Mob, float64) {
+	var closest *Mob
+	minDist := math.MaxFloat64
+
+	for _, mob := range g.mobs {
+		if mob.Zone != zone || !mob.IsAlive() {
+			continue
+		}
+		dx := x - mob.X
+		dy := y - mob.Y
+		if dist := dx*dx + dy*dy; dist < minDist {
+			minDist = dist
+			closest = mob
+		}
+	}
+
+	if closest == nil {
+		return nil, math.MaxFloat64
+	}
+	return closest, math.Sqrt(minDist)
+}
@@ -2,21 +2,48 @@ package game
 
 import "time"
 
+// Настройки владения дропом
+var DropSettings = struct {
+	Lifetime    time.Duration // сколько дроп лежит на земле
+	OwnerWindow time.Duration // сколько дроп может подобрать только владелец
+	PartyShared bool          // делить ли окно владельца с группой владельца
+}{
+	Lifetime:    30 * time.Second,
+	OwnerWindow: 10 * time.Second,
+	PartyShared: true,
+}
+
 type PetalDrop struct {
-	ID       string    `json:"id"`
-	Type     PetalType `json:"type"`
-	X        float64   `json:"x"`
-	Y        float64   `json:"y"`
-	OwnerID  string    `json:"owner_id"`
-	Zone     string    `json:"zone"`
-	Created  time.Time `json:"-"`
-	Lifetime time.Duration `json:"-"`
+	ID          string        `json:"id"`
+	Type        PetalType     `json:"type"`
+	Rarity      Rarity        `json:"rarity"`
+	X           float64       `json:"x"`
+	Y           float64       `json:"y"`
+	OwnerID     string        `json:"owner_id"`
+	PartyID     string        `json:"party_id,omitempty"`
+	Zone        string        `json:"zone"`
+	Created     time.Time     `json:"-"`
+	Lifetime    time.Duration `json:"-"`
+	OwnerWindow time.Duration `json:"-"`
 }
 
 func (d *PetalDrop) IsExpired() bool {
 	return time.Since(d.Created) > d.Lifetime
 }
 
-func (d *PetalDrop) CanBePickedBy(playerID string) bool {
-	return d.OwnerID == playerID
-}
\ No newline at end of file
+// IsFreeForAll — истекло ли окно владельца
+func (d *PetalDrop) IsFreeForAll() bool {
+	return time.Since(d.Created) >= d.OwnerWindow
+}
+
+// FreeAt — момент, когда дроп станет доступен всем
+func (d *PetalDrop) FreeAt() time.Time {
+	return d.Created.Add(d.OwnerWindow)
+}
+
+func (d *PetalDrop) CanBePickedBy(player *Player) bool {
+	if d.OwnerID == player.ID || d.IsFreeForAll() {
+		return true
+	}
+	return d.PartyID != "" && d.PartyID == player.PartyID
+}
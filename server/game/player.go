@@ -15,15 +15,28 @@ type Player struct {
 	Speed          float64   `json:"speed"`
 	PortalCooldown time.Time `json:"-"`
 	CurrentZone    string    `json:"currentZone"`
+	PartyID        string    `json:"party_id,omitempty"`
+	Stance         Stance    `json:"stance"`
 	Radius         float64   `json:"radius"`
 
-	Petals map[string]*Petal `json:"petals"`
+	Petals         map[string]*Petal `json:"petals"`
+	FormationAngle float64           `json:"-"` // текущий поворот кольца лепестков
+	Effects        StatusEffects     `json:"effects"`
 
 	Health          int       `json:"health"`
 	MaxHealth       int       `json:"max_health"`
 	CollisionDamage int       `json:"collision_damage"`
 	LastHitTime     time.Time `json:"-"` // Время последнего получения урона
 	LastAttackTime  time.Time `json:"-"` // Время последней атаки
+
+	Level          int             `json:"level"`
+	XP             int             `json:"xp"`
+	UnlockedZones  map[string]bool `json:"unlocked_zones"`
+	DefeatedBosses map[string]bool `json:"-"` // ID побеждённых энкаунтеров
+
+	Regions     []string `json:"regions,omitempty"` // области зоны, где стоит игрок
+	InSafeZone  bool     `json:"safe"`
+	regionCarry float64  // дробная часть урона/лечения областей
 }
 
 func NewPlayer(id, userID, username string, x, y float64, color string) *Player {
@@ -39,10 +52,15 @@ func NewPlayer(id, userID, username string, x, y float64, color string) *Player
 		Health:          100,
 		MaxHealth:       100,
 		CollisionDamage: 25, // Базовый урон игрока
+		Stance:          StanceNeutral,
 		LastHitTime:     time.Now(),
 		LastAttackTime:  time.Now(),
 
 		Petals: make(map[string]*Petal),
+
+		Level:          1,
+		UnlockedZones:  make(map[string]bool),
+		DefeatedBosses: make(map[string]bool),
 	}
 }
 
@@ -54,6 +72,9 @@ func (p *Player) DistanceTo(otherX, otherY float64) float64 {
 
 func (p *Player) TakeDamage(damage int) bool {
 	now := time.Now()
+	if p.InSafeZone {
+		return false // в безопасной области урон не проходит
+	}
 	if now.Sub(p.LastHitTime) < 100*time.Millisecond {
 		return false // Слишком рано для следующего удара
 	}
@@ -68,14 +89,22 @@ func (p *Player) TakeDamage(damage int) bool {
 	return true
 }
 
-// IsAlive проверяет, жив ли игрок
+// CanBeHitByMob — прошёл ли КД неуязвимости после удара моба
+func (p *Player) CanBeHitByMob() bool {
+	return time.Since(p.LastHitTime) >= 500*time.Millisecond
+}
+
+// TakeDamageFromMob наносит урон от моба с КД 500 мс
 func (p *Player) TakeDamageFromMob(damage int) bool {
 	now := time.Now()
+	if p.InSafeZone {
+		return false
+	}
 	if now.Sub(p.LastHitTime) < 500*time.Millisecond { // КД 500 мс между получением урона
 		return false
 	}
 
-	p.Health -= damage
+	p.Health -= scaleDamage(damage, p.Effects.IncomingDamageMultiplier())
 	p.LastHitTime = now
 
 	if p.Health < 0 {
@@ -96,12 +125,14 @@ func (p *Player) IsAlive() bool {
 }
 
 // Respawn возрождает игрока
-func (p *Player) Respawn(x, y float64) {
+func (p *Player) Respawn(x, y float64, zone string) {
 	p.Health = p.MaxHealth
 	p.X = x
 	p.Y = y
-	p.CurrentZone = "common"
+	p.CurrentZone = zone
 	p.LastHitTime = time.Now()
+	p.Effects = nil
+	p.RecomputeFormation()
 }
 
 // CanAttack проверяет, может ли игрок атаковать (прошло ли 500мс с последней атаки)
@@ -114,13 +145,39 @@ func (p *Player) MarkAttack() {
 	p.LastAttackTime = time.Now()
 }
 
-func (p *Player) AddPetal(petalType PetalType) {
-	petal := NewPetal(petalType, p.ID)
+// StanceDamageMultiplier — множитель урона лепестков от текущей стойки
+func (p *Player) StanceDamageMultiplier() float64 {
+	if cfg, ok := StanceConfigs[p.Stance]; ok {
+		return cfg.DamageMultiplier
+	}
+	return 1.0
+}
+
+// StanceIncomingMultiplier — множитель входящего урона от текущей стойки
+func (p *Player) StanceIncomingMultiplier() float64 {
+	if cfg, ok := StanceConfigs[p.Stance]; ok {
+		return cfg.IncomingDamageMultiplier
+	}
+	return 1.0
+}
+
+// StanceRadiusMultiplier — множитель радиуса орбиты от текущей стойки
+func (p *Player) StanceRadiusMultiplier() float64 {
+	if cfg, ok := StanceConfigs[p.Stance]; ok {
+		return cfg.RadiusMultiplier
+	}
+	return 1.0
+}
+
+func (p *Player) AddPetal(petalType PetalType, rarity Rarity) {
+	petal := NewPetal(petalType, rarity, p.ID)
 	p.Petals[petal.ID] = petal
+	p.RecomputeFormation()
 }
 
 func (p *Player) RemovePetal(petalID string) {
 	delete(p.Petals, petalID)
+	p.RecomputeFormation()
 }
 func (p *Player) RemoveAllPetals() {
 	if p.Petals == nil {
@@ -0,0 +1,112 @@
+package game
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+)
+
+// PlayerProgress — прогресс игрока, который переживает переподключение
+type PlayerProgress struct {
+	Level          int
+	XP             int
+	UnlockedZones  map[string]bool
+	DefeatedBosses map[string]bool
+}
+
+// ProgressStore — хранилище прогресса по userID (реализуется сервером)
+type ProgressStore interface {
+	SaveProgress(userID string, progress PlayerProgress) error
+}
+
+// progressSaver — очередь сохранений: на каждого пользователя хранится только
+// последний снимок, а запись идёт в отдельной горутине, не под g.mu
+type progressSaver struct {
+	mu      sync.Mutex
+	store   ProgressStore
+	pending map[string]PlayerProgress
+	wake    chan struct{}
+}
+
+func newProgressSaver() *progressSaver {
+	return &progressSaver{
+		pending: make(map[string]PlayerProgress),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// SetProgressStore — куда сохранять прогресс игроков (nil — не сохранять)
+func (g *Game) SetProgressStore(store ProgressStore) {
+	g.progress.mu.Lock()
+	defer g.progress.mu.Unlock()
+	g.progress.store = store
+}
+
+// Progress — снимок сохраняемого прогресса игрока
+func (p *Player) Progress() PlayerProgress {
+	return PlayerProgress{
+		Level:          p.Level,
+		XP:             p.XP,
+		UnlockedZones:  maps.Clone(p.UnlockedZones),
+		DefeatedBosses: maps.Clone(p.DefeatedBosses),
+	}
+}
+
+// applyProgress — восстанавливает прогресс из хранилища
+func (p *Player) applyProgress(progress PlayerProgress) {
+	if progress.Level > 0 {
+		p.Level = min(progress.Level, MaxPlayerLevel)
+		p.XP = progress.XP
+	}
+	maps.Copy(p.UnlockedZones, progress.UnlockedZones)
+	maps.Copy(p.DefeatedBosses, progress.DefeatedBosses)
+}
+
+// saveProgressLocked — ставит прогресс игрока в очередь на сохранение
+func (g *Game) saveProgressLocked(player *Player) {
+	if player.UserID == "" {
+		return
+	}
+	saver := g.progress
+	saver.mu.Lock()
+	if saver.store == nil {
+		saver.mu.Unlock()
+		return
+	}
+	saver.pending[player.UserID] = player.Progress()
+	saver.mu.Unlock()
+
+	select {
+	case saver.wake <- struct{}{}:
+	default: // сохранение уже запланировано
+	}
+}
+
+// progressLoop — пишет накопленный прогресс; при остановке игры дописывает остаток
+func (g *Game) progressLoop() {
+	for {
+		select {
+		case <-g.progress.wake:
+			g.progress.flush()
+		case <-g.done:
+			g.progress.flush()
+			return
+		}
+	}
+}
+
+func (s *progressSaver) flush() {
+	s.mu.Lock()
+	store, pending := s.store, s.pending
+	s.pending = make(map[string]PlayerProgress)
+	s.mu.Unlock()
+
+	if store == nil {
+		return
+	}
+	for userID, progress := range pending {
+		if err := store.SaveProgress(userID, progress); err != nil {
+			fmt.Printf("⚠️ Failed to save progress of %s: %v\n", userID, err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+// Параметры прогрессии игрока
+const (
+	MaxPlayerLevel       = 50
+	PortalRejectCooldown = 2 * time.Second // чтобы отказ не присылался каждый тик движения
+	xpPerLevelQuadratic  = 50              // XP до уровня n: 50·n·(n−1)
+)
+
+// XPForLevel — сколько всего опыта нужно, чтобы достичь уровня
+func XPForLevel(level int) int {
+	return xpPerLevelQuadratic * level * (level - 1)
+}
+
+// mobXP — опыт за убийство моба (та же формула, что приходит клиенту)
+func mobXP(mob *Mob) int {
+	return mob.MaxHealth / 2
+}
+
+// AddXP начисляет опыт и повышает уровень. Возвращает true, если уровень вырос.
+func (p *Player) AddXP(xp int) bool {
+	p.XP += xp
+	leveledUp := false
+	for p.Level < MaxPlayerLevel && p.XP >= XPForLevel(p.Level+1) {
+		p.Level++
+		leveledUp = true
+	}
+	return leveledUp
+}
+
+// HasKeyItem — есть ли у игрока лепесток-ключ нужного типа
+func (p *Player) HasKeyItem(item string) bool {
+	for _, petal := range p.Petals {
+		if string(petal.Type) == item {
+			return true
+		}
+	}
+	return false
+}
+
+// UnlockZone — открывает зону игроку навсегда (сохраняется вместе с прогрессом)
+func (p *Player) UnlockZone(zone string) {
+	if p.UnlockedZones == nil {
+		p.UnlockedZones = make(map[string]bool)
+	}
+	p.UnlockedZones[zone] = true
+}
+
+// CheckRequirements — проходит ли игрок требования портала.
+// Возвращает понятную причину отказа.
+func (r PortalRequirements) CheckRequirements(p *Player) (bool, string) {
+	if r.MinLevel > 0 && p.Level < r.MinLevel {
+		return false, fmt.Sprintf("Requires level %d (you are level %d)", r.MinLevel, p.Level)
+	}
+	if r.KeyItem != "" && !p.HasKeyItem(r.KeyItem) {
+		return false, fmt.Sprintf("Requires a %s petal", r.KeyItem)
+	}
+	if r.Boss != "" && !p.DefeatedBosses[r.Boss] {
+		return false, fmt.Sprintf("Defeat %s first", r.Boss)
+	}
+	return true, ""
+}
+
+// grantXPLocked — начисляет опыт и сообщает о новом уровне
+func (g *Game) grantXPLocked(player *Player, xp int) {
+	if xp <= 0 || !player.AddXP(xp) {
+		return
+	}
+	if conn, ok := g.connections[player.ID]; ok {
+		conn.WriteJSON(map[string]interface{}{
+			"type": "level_up",
+			"data": map[string]interface{}{
+				"level":      player.Level,
+				"xp":         player.XP,
+				"next_level": XPForLevel(player.Level + 1),
+			},
+		})
+	}
+	g.saveProgressLocked(player)
+	fmt.Printf("⭐ Player %s reached level %d\n", player.ID, player.Level)
+}
+
+// unlockZoneLocked — открывает зону и сохраняет прогресс, если она новая.
+// Возвращает true, если зона открылась только что.
+func (g *Game) unlockZoneLocked(player *Player, zone string) bool {
+	if player.UnlockedZones[zone] {
+		return false
+	}
+	player.UnlockZone(zone)
+	g.saveProgressLocked(player)
+	return true
+}
+
+// canEnterZoneLocked — пускает ли портал игрока в зону назначения.
+// Открытые зоны проверки не требуют; при первом проходе зона открывается.
+func (g *Game) canEnterZoneLocked(player *Player, fromPortal, toPortal *Portal) bool {
+	if player.UnlockedZones[toPortal.Zone] {
+		return true
+	}
+
+	if ok, reason := fromPortal.Requirements.CheckRequirements(player); !ok {
+		g.rejectPortalLocked(player, fromPortal, toPortal.Zone, reason)
+		return false
+	}
+
+	g.unlockZoneLocked(player, toPortal.Zone)
+	if conn, ok := g.connections[player.ID]; ok {
+		conn.WriteJSON(map[string]interface{}{
+			"type": "zone_unlocked",
+			"data": map[string]interface{}{
+				"zone": toPortal.Zone,
+			},
+		})
+	}
+	return true
+}
+
+// rejectPortalLocked — сообщает игроку, почему портал его не пустил
+func (g *Game) rejectPortalLocked(player *Player, portal *Portal, zone, reason string) {
+	player.PortalCooldown = time.Now().Add(PortalRejectCooldown)
+	if conn, ok := g.connections[player.ID]; ok {
+		conn.WriteJSON(map[string]interface{}{
+			"type": "portal_rejected",
+			"data": map[string]interface{}{
+				"portal_id":    portal.ID,
+				"zone":         zone,
+				"reason":       reason,
+				"requirements": portal.Requirements,
+			},
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Projectile — снаряд, выпущенный лепестком игрока или мобом (Hostile)
+type Projectile struct {
+	ID      string       `json:"id"`
+	OwnerID string       `json:"owner_id"` // ID игрока, а для Hostile — ID моба
+	Hostile bool         `json:"hostile,omitempty"`
+	Zone    string       `json:"zone"`
+	X       float64      `json:"x"`
+	Y       float64      `json:"y"`
+	VX      float64      `json:"vx"`
+	VY      float64      `json:"vy"`
+	Radius  float64      `json:"radius"`
+	Damage  int          `json:"-"`
+	OnHit   []EffectSpec `json:"-"`
+	Expires time.Time    `json:"-"`
+}
+
+const ProjectileRadius = 6.0
+
+// spawnProjectile — создаёт снаряд, летящий под углом angle (вызывается под g.mu)
+func (g *Game) spawnProjectile(player *Player, x, y, angle, speed float64, damage int, maxRange float64) *Projectile {
+	if speed <= 0 {
+		return nil
+	}
+
+	// Время жизни — сколько нужно, чтобы пролететь maxRange при 10 тиках в секунду
+	ticks := maxRange / speed
+	lifetime := time.Duration(ticks * float64(100*time.Millisecond))
+
+	g.entitySeq++
+	projectile := &Projectile{
+		ID:      fmt.Sprintf("proj_%d_%d", time.Now().UnixNano(), g.entitySeq),
+		OwnerID: player.ID,
+		Zone:    player.CurrentZone,
+		X:       x,
+		Y:       y,
+		VX:      math.Cos(angle) * speed,
+		VY:      math.Sin(angle) * speed,
+		Radius:  ProjectileRadius,
+		Damage:  damage,
+		Expires: time.Now().Add(lifetime),
+	}
+	g.projectiles[projectile.ID] = projectile
+	return projectile
+}
+
+// spawnMobProjectile — снаряд моба, который бьёт игроков (вызывается под g.mu)
+func (g *Game) spawnMobProjectile(mob *Mob, angle, speed float64, damage int, maxRange float64) *Projectile {
+	if speed <= 0 {
+		return nil
+	}
+
+	ticks := maxRange / speed
+	lifetime := time.Duration(ticks * float64(100*time.Millisecond))
+
+	g.entitySeq++
+	projectile := &Projectile{
+		ID:      fmt.Sprintf("proj_%d_%d", time.Now().UnixNano(), g.entitySeq),
+		OwnerID: mob.ID,
+		Hostile: true,
+		Zone:    mob.Zone,
+		X:       mob.X,
+		Y:       mob.Y,
+		VX:      math.Cos(angle) * speed,
+		VY:      math.Sin(angle) * speed,
+		Radius:  ProjectileRadius,
+		Damage:  damage,
+		Expires: time.Now().Add(lifetime),
+	}
+	g.projectiles[projectile.ID] = projectile
+	return projectile
+}
+
+// updateProjectilesLocked — двигает снаряды и проверяет попадания
+func (g *Game) updateProjectilesLocked(now time.Time) {
+	for id, projectile := range g.projectiles {
+		if projectile.Hostile {
+			g.updateMobProjectileLocked(id, projectile, now)
+			continue
+		}
+
+		owner := g.players[projectile.OwnerID]
+		if owner == nil || now.After(projectile.Expires) {
+			delete(g.projectiles, id)
+			continue
+		}
+
+		projectile.X += projectile.VX
+		projectile.Y += projectile.VY
+		if g.projectileBlockedLocked(projectile) {
+			delete(g.projectiles, id)
+			continue
+		}
+
+		for _, mob := range g.mobs {
+			if !mob.IsAlive() || mob.Zone != projectile.Zone {
+				continue
+			}
+			if mob.DistanceTo(projectile.X, projectile.Y) < mob.Radius+projectile.Radius {
+				mob.Effects.ApplyAll(projectile.OnHit, owner.ID, now)
+				g.damageMobByPlayer(owner, mob, projectile.Damage)
+				delete(g.projectiles, id)
+				break
+			}
+		}
+	}
+}
+
+// updateMobProjectileLocked — снаряд моба летит и бьёт первого задетого игрока
+func (g *Game) updateMobProjectileLocked(id string, projectile *Projectile, now time.Time) {
+	mob := g.mobs[projectile.OwnerID]
+	if mob == nil || now.After(projectile.Expires) {
+		delete(g.projectiles, id)
+		return
+	}
+
+	projectile.X += projectile.VX
+	projectile.Y += projectile.VY
+	if g.projectileBlockedLocked(projectile) {
+		delete(g.projectiles, id)
+		return
+	}
+
+	for _, player := range g.players {
+		if !player.IsAlive() || player.CurrentZone != projectile.Zone {
+			continue
+		}
+		if player.DistanceTo(projectile.X, projectile.Y) < player.Radius+projectile.Radius {
+			g.mobHitPlayerLocked(mob, player, projectile.Damage, now)
+			delete(g.projectiles, id)
+			return
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// RegionKind — тип области внутри зоны
+type RegionKind string
+
+const (
+	RegionSafe     RegionKind = "safe"     // мобы не агрятся, урон по игрокам не проходит
+	RegionHazard   RegionKind = "hazard"   // урон со временем всем, кто внутри
+	RegionFountain RegionKind = "fountain" // лечит игроков
+)
+
+// RegionShape — форма области
+type RegionShape string
+
+const (
+	RegionCircle RegionShape = "circle"
+	RegionRect   RegionShape = "rect"
+)
+
+// Значения по умолчанию для областей
+const (
+	DefaultHazardDamagePerSecond = 6.0
+	DefaultFountainHealPerSecond = 10.0
+)
+
+// Region — типизированная область зоны из файла карты
+type Region struct {
+	ID    string      `json:"id"`
+	Zone  string      `json:"zone"`
+	Kind  RegionKind  `json:"kind"`
+	Name  string      `json:"name,omitempty"`
+	Shape RegionShape `json:"shape"`
+	// circle
+	X      float64 `json:"x,omitempty"`
+	Y      float64 `json:"y,omitempty"`
+	Radius float64 `json:"radius,omitempty"`
+	// rect
+	MinX float64 `json:"min_x,omitempty"`
+	MaxX float64 `json:"max_x,omitempty"`
+	MinY float64 `json:"min_y,omitempty"`
+	MaxY float64 `json:"max_y,omitempty"`
+
+	DamagePerSecond float64 `json:"damage_per_second,omitempty"` // hazard
+	HealPerSecond   float64 `json:"heal_per_second,omitempty"`   // fountain
+}
+
+// validate проверяет область из файла карты
+func (r *Region) validate(zone *Zone) error {
+	switch r.Kind {
+	case RegionSafe, RegionHazard, RegionFountain:
+	default:
+		return fmt.Errorf("region %q: unknown kind %q", r.ID, r.Kind)
+	}
+	switch r.Shape {
+	case RegionCircle:
+		if r.Radius <= 0 {
+			return fmt.Errorf("region %q: circle needs positive radius", r.ID)
+		}
+		if !zone.Contains(r.X, r.Y) {
+			return fmt.Errorf("region %q: center is outside zone %q", r.ID, zone.Name)
+		}
+	case RegionRect:
+		if r.MinX >= r.MaxX || r.MinY >= r.MaxY {
+			return fmt.Errorf("region %q: empty bounds", r.ID)
+		}
+		if r.MinX < zone.MinX || r.MaxX > zone.MaxX || r.MinY < zone.MinY || r.MaxY > zone.MaxY {
+			return fmt.Errorf("region %q: outside zone %q", r.ID, zone.Name)
+		}
+	default:
+		return fmt.Errorf("region %q: unknown shape %q", r.ID, r.Shape)
+	}
+	if r.DamagePerSecond < 0 || r.HealPerSecond < 0 {
+		return fmt.Errorf("region %q: negative damage or heal", r.ID)
+	}
+	return nil
+}
+
+// Contains — лежит ли точка внутри области
+func (r *Region) Contains(x, y float64) bool {
+	if r.Shape == RegionCircle {
+		dx, dy := x-r.X, y-r.Y
+		return dx*dx+dy*dy <= r.Radius*r.Radius
+	}
+	return x >= r.MinX && x <= r.MaxX && y >= r.MinY && y <= r.MaxY
+}
+
+// bounds — описывающий прямоугольник области
+func (r *Region) bounds() (minX, maxX, minY, maxY float64) {
+	if r.Shape == RegionCircle {
+		return r.X - r.Radius, r.X + r.Radius, r.Y - r.Radius, r.Y + r.Radius
+	}
+	return r.MinX, r.MaxX, r.MinY, r.MaxY
+}
+
+// safeRegion — первая безопасная область зоны (там появляются игроки)
+func (z *Zone) safeRegion() *Region {
+	for _, r := range z.Regions {
+		if r.Kind == RegionSafe {
+			return r
+		}
+	}
+	return nil
+}
+
+// inSafeRegionLocked — лежит ли точка в безопасной области зоны
+func (g *Game) inSafeRegionLocked(zoneName string, x, y float64) bool {
+	zone := g.zones[zoneName]
+	if zone == nil {
+		return false
+	}
+	for _, r := range zone.Regions {
+		if r.Kind == RegionSafe && r.Contains(x, y) {
+			return true
+		}
+	}
+	return false
+}
+
+// regionLoop — эффекты областей 10 раз в секунду
+func (g *Game) regionLoop() {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	lastUpdate := time.Now()
+	for g.tick(ticker) {
+		now := time.Now()
+		g.updateRegions(now.Sub(lastUpdate).Seconds())
+		lastUpdate = now
+	}
+}
+
+func (g *Game) updateRegions(deltaTime float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, player := range g.players {
+		g.updatePlayerRegionsLocked(player, deltaTime)
+	}
+	for _, mob := range g.mobs {
+		g.updateMobRegionsLocked(mob, deltaTime)
+	}
+}
+
+// updatePlayerRegionsLocked — вход/выход из областей, урон и лечение игрока
+func (g *Game) updatePlayerRegionsLocked(player *Player, deltaTime float64) {
+	var inside []string
+	safe := false
+	change := 0.0 // > 0 — лечение, < 0 — урон
+	if zone := g.zones[player.CurrentZone]; zone != nil && player.IsAlive() {
+		for _, r := range zone.Regions {
+			if !r.Contains(player.X, player.Y) {
+				continue
+			}
+			inside = append(inside, r.ID)
+			switch r.Kind {
+			case RegionSafe:
+				safe = true
+			case RegionHazard:
+				change -= r.damagePerSecond() * deltaTime
+			case RegionFountain:
+				change += r.healPerSecond() * deltaTime
+			}
+		}
+	}
+	if safe && change < 0 {
+		change = 0 // в безопасной области урон не проходит
+	}
+
+	g.reportRegionChangesLocked(player, inside)
+	player.Regions = inside
+	player.InSafeZone = safe
+
+	if change == 0 {
+		player.regionCarry = 0
+		return
+	}
+	player.regionCarry += change
+	amount := int(player.regionCarry)
+	player.regionCarry -= float64(amount)
+
+	switch {
+	case amount > 0 && player.Health < player.MaxHealth:
+		player.Health = min(player.MaxHealth, player.Health+amount)
+	case amount < 0:
+		damage := -amount
+		player.Health = max(0, player.Health-damage)
+		g.sendDamageNotification(player, damage)
+		if !player.IsAlive() {
+			g.handlePlayerDeath(player)
+		}
+	}
+}
+
+// updateMobRegionsLocked — опасные области ранят и мобов
+func (g *Game) updateMobRegionsLocked(mob *Mob, deltaTime float64) {
+	zone := g.zones[mob.Zone]
+	if zone == nil || !mob.IsAlive() {
+		return
+	}
+	damage := 0.0
+	for _, r := range zone.Regions {
+		if r.Kind == RegionHazard && r.Contains(mob.X, mob.Y) {
+			damage += r.damagePerSecond() * deltaTime
+		}
+	}
+	if damage == 0 {
+		mob.regionCarry = 0
+		return
+	}
+	mob.regionCarry += damage
+	if hit := int(mob.regionCarry); hit > 0 {
+		mob.regionCarry -= float64(hit)
+		// Убийство областью засчитывается игроку с наибольшей угрозой
+		g.damageMobLocked(mob, hit, nil)
+	}
+}
+
+// reportRegionChangesLocked — сообщает игроку, в какие области он вошёл и из каких вышел
+func (g *Game) reportRegionChangesLocked(player *Player, inside []string) {
+	conn, ok := g.connections[player.ID]
+	if !ok {
+		return
+	}
+	for _, id := range inside {
+		if !slices.Contains(player.Regions, id) {
+			conn.WriteJSON(map[string]interface{}{
+				"type": "region_entered",
+				"data": g.regionMessageLocked(player.CurrentZone, id),
+			})
+		}
+	}
+	for _, id := range player.Regions {
+		if !slices.Contains(inside, id) {
+			conn.WriteJSON(map[string]interface{}{
+				"type": "region_left",
+				"data": map[string]interface{}{"id": id},
+			})
+		}
+	}
+}
+
+func (g *Game) regionMessageLocked(zoneName, id string) map[string]interface{} {
+	data := map[string]interface{}{"id": id}
+	if zone := g.zones[zoneName]; zone != nil {
+		for _, r := range zone.Regions {
+			if r.ID == id {
+				data["kind"] = r.Kind
+				data["name"] = r.Name
+			}
+		}
+	}
+	return data
+}
+
+func (r *Region) damagePerSecond() float64 {
+	if r.DamagePerSecond > 0 {
+		return r.DamagePerSecond
+	}
+	return DefaultHazardDamagePerSecond
+}
+
+func (r *Region) healPerSecond() float64 {
+	if r.HealPerSecond > 0 {
+		return r.HealPerSecond
+	}
+	return DefaultFountainHealPerSecond
+}
@@ -0,0 +1,83 @@
+package game
+
+import (
+	"fmt"
+	"math"
+)
+
+// SeamlessViewDistance — насколько далеко за границу своей зоны видит игрок
+// на бесшовной карте
+const SeamlessViewDistance = 600.0
+
+// touches — соприкасаются ли зоны общей границей ненулевой длины
+func (z *Zone) touches(other *Zone) bool {
+	overlapX := math.Min(z.MaxX, other.MaxX) - math.Max(z.MinX, other.MinX)
+	overlapY := math.Min(z.MaxY, other.MaxY) - math.Max(z.MinY, other.MinY)
+	sharedVertical := (z.MaxX == other.MinX || other.MaxX == z.MinX) && overlapY > 0
+	sharedHorizontal := (z.MaxY == other.MinY || other.MaxY == z.MinY) && overlapX > 0
+	return sharedVertical || sharedHorizontal
+}
+
+// distanceTo — расстояние от точки до прямоугольника зоны (0 внутри)
+func (z *Zone) distanceTo(x, y float64) float64 {
+	dx := math.Max(0, math.Max(z.MinX-x, x-z.MaxX))
+	dy := math.Max(0, math.Max(z.MinY-y, y-z.MaxY))
+	return math.Sqrt(dx*dx + dy*dy)
+}
+
+// mapZoneAtLocked — зона карты, в которой лежит точка (инстансы не учитываются)
+func (g *Game) mapZoneAtLocked(x, y float64) *Zone {
+	for _, z := range g.mapDef.Zones {
+		if z.Contains(x, y) {
+			return z
+		}
+	}
+	return nil
+}
+
+// crossZoneBoundaryLocked — на бесшовной карте переводит игрока в соседнюю
+// зону, если он шагнул через общую границу. В промежутки между зонами
+// не пускает — туда позицию обрежет constrainToZone.
+func (g *Game) crossZoneBoundaryLocked(player *Player, x, y float64) {
+	if !g.mapDef.Seamless || g.dungeons[player.CurrentZone] != nil {
+		return
+	}
+	current := g.zones[player.CurrentZone]
+	if current != nil && current.Contains(x, y) {
+		return
+	}
+	next := g.mapZoneAtLocked(x, y)
+	if next == nil || next.Name == player.CurrentZone {
+		return
+	}
+
+	from := player.CurrentZone
+	player.CurrentZone = next.Name
+	g.unlockZoneLocked(player, next.Name)
+
+	if conn, ok := g.connections[player.ID]; ok {
+		conn.WriteJSON(map[string]interface{}{
+			"type": "zone_changed",
+			"data": map[string]interface{}{
+				"fromZone": from,
+				"toZone":   next.Name,
+			},
+		})
+	}
+	g.sendZoneObstaclesLocked(player)
+
+	fmt.Printf("🚶 %s walked from %s to %s\n", player.ID, from, next.Name)
+}
+
+// visibleFromZoneLocked — видна ли сущность игрокам зоны zone.
+// На бесшовной карте видно и соседей у самой границы.
+func (g *Game) visibleFromZoneLocked(zone, entityZone string, x, y float64) bool {
+	if entityZone == zone {
+		return true
+	}
+	if !g.mapDef.Seamless || g.dungeons[zone] != nil || g.dungeons[entityZone] != nil {
+		return false
+	}
+	z := g.zones[zone]
+	return z != nil && z.distanceTo(x, y) <= SeamlessViewDistance
+}
@@ -0,0 +1,42 @@
+package game
+
+// Stance — стойка игрока, управляющая орбитой лепестков
+type Stance string
+
+const (
+	StanceNeutral Stance = "neutral"
+	StanceAttack  Stance = "attack"
+	StanceDefend  Stance = "defend"
+)
+
+// Конфигурация стоек
+var StanceConfigs = map[Stance]struct {
+	RadiusMultiplier         float64 // множитель радиуса орбиты
+	DamageMultiplier         float64 // множитель урона лепестков
+	IncomingDamageMultiplier float64 // множитель входящего урона (игрок и лепестки)
+}{
+	StanceNeutral: {RadiusMultiplier: 1.0, DamageMultiplier: 1.0, IncomingDamageMultiplier: 1.0},
+	StanceAttack:  {RadiusMultiplier: 1.6, DamageMultiplier: 1.25, IncomingDamageMultiplier: 1.2},
+	StanceDefend:  {RadiusMultiplier: 0.55, DamageMultiplier: 0.6, IncomingDamageMultiplier: 0.7},
+}
+
+// StanceRadiusLerpSpeed — скорость перехода радиуса орбиты к целевому (доля в секунду)
+const StanceRadiusLerpSpeed = 6.0
+
+// IsValidStance проверяет, что стойка известна
+func IsValidStance(s Stance) bool {
+	_, ok := StanceConfigs[s]
+	return ok
+}
+
+// scaleDamage — применяет множитель к целочисленному урону (минимум 1, если урон был)
+func scaleDamage(damage int, multiplier float64) int {
+	if damage <= 0 {
+		return damage
+	}
+	scaled := int(float64(damage)*multiplier + 0.5)
+	if scaled < 1 {
+		scaled = 1
+	}
+	return scaled
+}
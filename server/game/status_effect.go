@@ -0,0 +1,249 @@
+package game
+
+import (
+	"math/rand"
+	"time"
+)
+
+// EffectType — тип статус-эффекта
+type EffectType string
+
+const (
+	EffectPoison       EffectType = "poison"       // урон за секунду (Magnitude — урон/сек за стак)
+	EffectSlow         EffectType = "slow"         // замедление (Magnitude — доля 0..1)
+	EffectStun         EffectType = "stun"         // не может двигаться и атаковать
+	EffectRegeneration EffectType = "regeneration" // лечение за секунду (Magnitude — хп/сек за стак)
+	EffectDamageAmp    EffectType = "damage_amp"   // получает больше урона (Magnitude — +доля за стак)
+	EffectArmor        EffectType = "armor"        // получает меньше урона (Magnitude — −доля за стак)
+)
+
+// StackRule — что делать при повторном наложении эффекта того же типа
+type StackRule string
+
+const (
+	StackRefresh StackRule = "refresh" // обновить длительность, сила берётся максимальная
+	StackAdd     StackRule = "stack"   // добавить стак (до MaxStacks) и обновить длительность
+	StackIgnore  StackRule = "ignore"  // оставить действующий эффект как есть
+)
+
+// EffectSpec — описание эффекта в конфиге мобов и лепестков
+type EffectSpec struct {
+	Type      EffectType
+	Duration  float64 // секунды
+	Magnitude float64
+	Stacking  StackRule
+	MaxStacks int
+	Chance    float64 // шанс наложения при ударе (0 — всегда)
+}
+
+// StatusEffect — активный эффект на сущности
+type StatusEffect struct {
+	Type        EffectType `json:"type"`
+	Source      string     `json:"source"` // ID игрока или моба, наложившего эффект
+	Magnitude   float64    `json:"magnitude"`
+	Stacks      int        `json:"stacks"`
+	RemainingMs int64      `json:"remaining_ms"`
+	Expires     time.Time  `json:"-"`
+	LastTick    time.Time  `json:"-"`
+}
+
+// StatusEffects — набор активных эффектов (игрок, моб или лепесток)
+type StatusEffects []*StatusEffect
+
+// Apply накладывает эффект по спецификации с учётом правила стака
+func (e *StatusEffects) Apply(spec EffectSpec, source string, now time.Time) {
+	duration := time.Duration(spec.Duration * float64(time.Second))
+
+	for _, effect := range *e {
+		if effect.Type != spec.Type {
+			continue
+		}
+		switch spec.Stacking {
+		case StackIgnore:
+		case StackAdd:
+			if spec.MaxStacks <= 0 || effect.Stacks < spec.MaxStacks {
+				effect.Stacks++
+			}
+			effect.Expires = now.Add(duration)
+			effect.Source = source
+		default: // StackRefresh
+			if spec.Magnitude > effect.Magnitude {
+				effect.Magnitude = spec.Magnitude
+			}
+			effect.Expires = now.Add(duration)
+			effect.Source = source
+		}
+		return
+	}
+
+	*e = append(*e, &StatusEffect{
+		Type:      spec.Type,
+		Source:    source,
+		Magnitude: spec.Magnitude,
+		Stacks:    1,
+		Expires:   now.Add(duration),
+		LastTick:  now,
+	})
+}
+
+// ApplyAll накладывает эффекты из конфига с учётом их шанса
+func (e *StatusEffects) ApplyAll(specs []EffectSpec, source string, now time.Time) {
+	for _, spec := range specs {
+		if spec.Chance > 0 && rand.Float64() >= spec.Chance {
+			continue
+		}
+		e.Apply(spec, source, now)
+	}
+}
+
+// Tick убирает истёкшие эффекты и возвращает урон и лечение, накопленные
+// периодическими эффектами (раз в секунду), а также источник урона.
+func (e *StatusEffects) Tick(now time.Time) (damage, heal int, damageSource string) {
+	active := (*e)[:0]
+	for _, effect := range *e {
+		if now.After(effect.Expires) {
+			continue
+		}
+		active = append(active, effect)
+
+		if now.Sub(effect.LastTick) < time.Second {
+			continue
+		}
+		effect.LastTick = now
+
+		amount := int(effect.Magnitude * float64(effect.Stacks))
+		switch effect.Type {
+		case EffectPoison:
+			damage += amount
+			damageSource = effect.Source
+		case EffectRegeneration:
+			heal += amount
+		}
+	}
+	*e = active
+	return damage, heal, damageSource
+}
+
+// total — суммарная сила эффектов типа t
+func (e StatusEffects) total(t EffectType) float64 {
+	sum := 0.0
+	for _, effect := range e {
+		if effect.Type == t {
+			sum += effect.Magnitude * float64(effect.Stacks)
+		}
+	}
+	return sum
+}
+
+// IsStunned — оглушена ли сущность
+func (e StatusEffects) IsStunned() bool {
+	return e.total(EffectStun) > 0
+}
+
+// SpeedMultiplier — множитель скорости с учётом замедления и оглушения
+func (e StatusEffects) SpeedMultiplier() float64 {
+	if e.IsStunned() {
+		return 0
+	}
+	slow := e.total(EffectSlow)
+	if slow > 0.9 {
+		slow = 0.9
+	}
+	return 1 - slow
+}
+
+// IncomingDamageMultiplier — множитель входящего урона (усиление и броня)
+func (e StatusEffects) IncomingDamageMultiplier() float64 {
+	armor := e.total(EffectArmor)
+	if armor > 0.8 {
+		armor = 0.8
+	}
+	return (1 + e.total(EffectDamageAmp)) * (1 - armor)
+}
+
+// Snapshot — копия эффектов для отправки клиенту
+func (e StatusEffects) Snapshot(now time.Time) StatusEffects {
+	snapshot := make(StatusEffects, 0, len(e))
+	for _, effect := range e {
+		copied := *effect
+		copied.RemainingMs = effect.Expires.Sub(now).Milliseconds()
+		snapshot = append(snapshot, &copied)
+	}
+	return snapshot
+}
+
+// statusEffectLoop — обрабатывает периодические эффекты 10 раз в секунду
+func (g *Game) statusEffectLoop() {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for g.tick(ticker) {
+		g.updateStatusEffects()
+	}
+}
+
+func (g *Game) updateStatusEffects() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+
+	for _, player := range g.players {
+		damage, heal, _ := player.Effects.Tick(now)
+		if !player.IsAlive() {
+			continue
+		}
+
+		player.Health += heal
+		if player.Health > player.MaxHealth {
+			player.Health = player.MaxHealth
+		}
+
+		if damage > 0 && !player.InSafeZone {
+			player.Health -= damage
+			if player.Health < 0 {
+				player.Health = 0
+			}
+			g.sendDamageNotification(player, damage)
+			if !player.IsAlive() {
+				g.handlePlayerDeath(player)
+			}
+		}
+
+		for _, petal := range player.Petals {
+			damage, heal, _ := petal.Effects.Tick(now)
+			if !petal.IsActive {
+				continue
+			}
+			petal.Health += heal
+			if petal.Health > petal.MaxHealth {
+				petal.Health = petal.MaxHealth
+			}
+			if damage > 0 {
+				petal.TakeDamage(damage)
+				if !petal.IsActive {
+					g.handlePetalDestroyed(petal)
+				}
+			}
+		}
+	}
+
+	for _, mob := range g.mobs {
+		damage, heal, source := mob.Effects.Tick(now)
+		if !mob.IsAlive() {
+			continue
+		}
+
+		mob.Health += heal
+		if mob.Health > mob.MaxHealth {
+			mob.Health = mob.MaxHealth
+		}
+		g.updateAffixRegenLocked(mob, now, 0.1)
+
+		if damage > 0 {
+			// Убийство ядом засчитывается игроку, наложившему эффект
+			// (если он ушёл — игроку с наибольшей угрозой)
+			g.damageMobLocked(mob, damage, g.players[source])
+		}
+	}
+}
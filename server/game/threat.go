@@ -0,0 +1,90 @@
+package game
+
+import "time"
+
+// Параметры таблицы угрозы
+const (
+	ThreatPerDamage = 1.0  // угроза за единицу нанесённого урона
+	ThreatPerHeal   = 0.5  // угроза за единицу лечения (для мобов, которые уже бьются с игроком)
+	ThreatDecay     = 0.97 // множитель угрозы за тик (100 мс)
+	ThreatMin       = 0.5  // ниже этого запись удаляется
+)
+
+// addThreatLocked — добавляет угрозу игроку в таблице моба
+func (g *Game) addThreatLocked(mob *Mob, playerID string, amount float64) {
+	if amount <= 0 {
+		return
+	}
+	if mob.Threat == nil {
+		mob.Threat = make(map[string]float64)
+	}
+	mob.Threat[playerID] += amount
+}
+
+// addHealingThreatLocked — лечение игрока злит мобов, у которых он уже в таблице
+func (g *Game) addHealingThreatLocked(player *Player, amount int) {
+	for _, mob := range g.mobs {
+		if mob.Zone != player.CurrentZone {
+			continue
+		}
+		if _, engaged := mob.Threat[player.ID]; engaged {
+			g.addThreatLocked(mob, player.ID, float64(amount)*ThreatPerHeal)
+		}
+	}
+}
+
+// updateThreatLocked — угасание угрозы, угроза от близости и очистка
+// записей игроков, которые умерли или ушли из зоны
+func (g *Game) updateThreatLocked(mob *Mob, config MobConfig, deltaTime float64) {
+	for playerID, threat := range mob.Threat {
+		player := g.players[playerID]
+		threat *= ThreatDecay
+		if player == nil || !player.IsAlive() || player.CurrentZone != mob.Zone || player.InSafeZone || threat < ThreatMin {
+			delete(mob.Threat, playerID)
+			continue
+		}
+		mob.Threat[playerID] = threat
+	}
+
+	if config.ProximityThreat <= 0 {
+		return
+	}
+	for _, player := range g.players {
+		if player.CurrentZone != mob.Zone || !player.IsAlive() || player.InSafeZone {
+			continue
+		}
+		distance := mob.DistanceTo(player.X, player.Y)
+		if distance < mob.DetectionRange {
+			closeness := 1 - distance/mob.DetectionRange
+			g.addThreatLocked(mob, player.ID, config.ProximityThreat*closeness*deltaTime)
+		}
+	}
+}
+
+// selectTargetLocked — игрок с наибольшей угрозой; если таблица пуста —
+// ближайший игрок в зоне (нужен автомату для проверок дистанции)
+func (g *Game) selectTargetLocked(mob *Mob) (*Player, float64) {
+	target := g.topThreatPlayerLocked(mob)
+	if target == nil {
+		return g.findClosestPlayerInZoneLocked(mob.X, mob.Y, mob.Zone)
+	}
+	return target, mob.DistanceTo(target.X, target.Y)
+}
+
+// topThreatPlayerLocked — игрок с наибольшей угрозой (nil — таблица пуста)
+func (g *Game) topThreatPlayerLocked(mob *Mob) *Player {
+	var target *Player
+	best := 0.0
+	for playerID, threat := range mob.Threat {
+		if player := g.players[playerID]; player != nil && threat > best {
+			target = player
+			best = threat
+		}
+	}
+	return target
+}
+
+// IsRetaliating — получал ли моб урон за последние window
+func (m *Mob) IsRetaliating(now time.Time, window time.Duration) bool {
+	return !m.LastHitTime.IsZero() && now.Sub(m.LastHitTime) <= window
+}
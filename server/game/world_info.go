@@ -0,0 +1,262 @@
+package game
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+// ZoneInfo — зона для миникарты клиента
+type ZoneInfo struct {
+	Name  string  `json:"name"`
+	MinX  float64 `json:"min_x"`
+	MaxX  float64 `json:"max_x"`
+	MinY  float64 `json:"min_y"`
+	MaxY  float64 `json:"max_y"`
+	Color string  `json:"color"`
+}
+
+// PortalInfo — портал с зоной назначения, чтобы клиенту не искать пару
+type PortalInfo struct {
+	ID           string             `json:"id"`
+	Zone         string             `json:"zone"`
+	X            float64            `json:"x"`
+	Y            float64            `json:"y"`
+	Radius       float64            `json:"radius"`
+	To           string             `json:"to"`
+	ToZone       string             `json:"to_zone"`
+	Requirements PortalRequirements `json:"requirements"`
+	Dungeon      string             `json:"dungeon,omitempty"`
+}
+
+// MobTypeInfo — базовые характеристики моба для подсказок
+// (итоговые значения = база × множитель редкости)
+type MobTypeInfo struct {
+	Type           MobType  `json:"type"`
+	Health         int      `json:"health"`
+	Damage         int      `json:"damage"`
+	Speed          float64  `json:"speed"`
+	Radius         float64  `json:"radius"`
+	DetectionRange float64  `json:"detection_range"`
+	Abilities      []string `json:"abilities,omitempty"`
+	Unique         bool     `json:"unique,omitempty"`
+}
+
+// PetalTypeInfo — базовые характеристики лепестка для подсказок
+type PetalTypeInfo struct {
+	Type        PetalType          `json:"type"`
+	Health      int                `json:"health"`
+	Damage      int                `json:"damage"`
+	HealAmount  int                `json:"heal_amount,omitempty"`
+	HealRate    float64            `json:"heal_rate,omitempty"`
+	Radius      float64            `json:"radius"`
+	ClusterSize int                `json:"cluster_size,omitempty"`
+	Behavior    string             `json:"behavior"`
+	ReloadTimes map[Rarity]float64 `json:"reload_times"` // секунды по редкостям
+}
+
+// RarityInfo — множители характеристик редкости
+type RarityInfo struct {
+	Rarity    Rarity  `json:"rarity"`
+	MobHealth float64 `json:"mob_health"`
+	MobDamage float64 `json:"mob_damage"`
+	MobRadius float64 `json:"mob_radius"`
+	MobSpeed  float64 `json:"mob_speed"`
+	Petal     float64 `json:"petal"`
+}
+
+// WorldInfo — статическое описание мира, чтобы клиент не хардкодил конфиги
+type WorldInfo struct {
+	Map        string          `json:"map"`
+	Width      float64         `json:"width"`
+	Height     float64         `json:"height"`
+	SpawnZone  string          `json:"spawn_zone"`
+	Seamless   bool            `json:"seamless"`
+	Zones      []ZoneInfo      `json:"zones"`
+	Portals    []PortalInfo    `json:"portals"`
+	POIs       []*POI          `json:"pois"`
+	Regions    []*Region       `json:"regions"`
+	MobTypes   []MobTypeInfo   `json:"mob_types"`
+	PetalTypes []PetalTypeInfo `json:"petal_types"`
+	Rarities   []RarityInfo    `json:"rarities"`
+}
+
+// worldInfoLocked собирает описание мира из текущей карты и конфигов
+func (g *Game) worldInfoLocked() WorldInfo {
+	m := g.mapDef
+	info := WorldInfo{
+		Map:       m.Name,
+		Width:     m.Width,
+		Height:    m.Height,
+		SpawnZone: m.SpawnZone,
+		Seamless:  m.Seamless,
+		POIs:      m.POIs,
+		Regions:   m.Regions,
+	}
+
+	for _, z := range m.Zones {
+		info.Zones = append(info.Zones, ZoneInfo{
+			Name: z.Name, MinX: z.MinX, MaxX: z.MaxX, MinY: z.MinY, MaxY: z.MaxY, Color: z.Color,
+		})
+	}
+
+	for _, p := range m.Portals {
+		portal := PortalInfo{
+			ID: p.ID, Zone: p.Zone, X: p.X, Y: p.Y, Radius: p.Radius, To: p.To,
+			Requirements: p.Requirements, Dungeon: p.Dungeon,
+		}
+		if dest := g.portals[p.To]; dest != nil {
+			portal.ToZone = dest.Zone
+		}
+		info.Portals = append(info.Portals, portal)
+	}
+
+	for mobType, config := range MobConfigs {
+		info.MobTypes = append(info.MobTypes, MobTypeInfo{
+			Type:           mobType,
+			Health:         config.Health,
+			Damage:         config.Damage,
+			Speed:          config.Speed,
+			Radius:         config.Radius,
+			DetectionRange: config.DetectionRange,
+			Abilities:      config.Abilities,
+			Unique:         config.Unique,
+		})
+	}
+	sort.Slice(info.MobTypes, func(i, j int) bool { return info.MobTypes[i].Type < info.MobTypes[j].Type })
+
+	for petalType, config := range PetalConfigs {
+		info.PetalTypes = append(info.PetalTypes, PetalTypeInfo{
+			Type:        petalType,
+			Health:      config.Health,
+			Damage:      config.Damage,
+			HealAmount:  config.HealAmount,
+			HealRate:    config.HealRate,
+			Radius:      config.Radius,
+			ClusterSize: config.ClusterSize,
+			Behavior:    config.Behavior,
+			ReloadTimes: config.ReloadTimes,
+		})
+	}
+	sort.Slice(info.PetalTypes, func(i, j int) bool { return info.PetalTypes[i].Type < info.PetalTypes[j].Type })
+
+	for _, rarity := range rarityOrder {
+		mult := RarityMultipliers[rarity]
+		info.Rarities = append(info.Rarities, RarityInfo{
+			Rarity:    rarity,
+			MobHealth: mult.HealthMultiplier,
+			MobDamage: mult.DamageMultiplier,
+			MobRadius: mult.RadiusMultiplier,
+			MobSpeed:  mult.SpeedMultiplier,
+			Petal:     PetalRarityMultipliers[rarity],
+		})
+	}
+
+	return info
+}
+
+// sendWorldInfoLocked — описание мира одному игроку
+func (g *Game) sendWorldInfoLocked(playerID string) {
+	if conn, ok := g.connections[playerID]; ok {
+		conn.WriteJSON(map[string]interface{}{
+			"type": "world_info",
+			"data": g.worldInfoLocked(),
+		})
+	}
+}
+
+// broadcastWorldInfoLocked — описание мира всем подключённым игрокам
+func (g *Game) broadcastWorldInfoLocked() {
+	info := g.worldInfoLocked()
+	for _, conn := range g.connections {
+		conn.WriteJSON(map[string]interface{}{
+			"type": "world_info",
+			"data": info,
+		})
+	}
+}
+
+// ReloadContent перечитывает карты и автоматы мобов из fsys (nil — встроенный
+// контент), подменяет глобальный контент и пересобирает эту игру.
+// При ошибке ничего не меняется. Если игр несколько, контент нужно разобрать
+// один раз (LoadContent), проверить каждой игрой (CheckContent), установить
+// (InstallContent) и только затем применить (ApplyContent).
+func (g *Game) ReloadContent(fsys fs.FS) error {
+	c, err := LoadContent(fsys)
+	if err != nil {
+		return fmt.Errorf("reload: %w", err)
+	}
+	if err := g.CheckContent(c); err != nil {
+		return err
+	}
+	InstallContent(c)
+	return g.ApplyContent(c)
+}
+
+// CheckContent — найдётся ли в контенте c карта этой игры
+func (g *Game) CheckContent(c *Content) error {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	_, err := g.contentMapLocked(c)
+	return err
+}
+
+// contentMapLocked — текущая карта игры из контента c
+func (g *Game) contentMapLocked(c *Content) (*MapDef, error) {
+	if m, ok := c.Maps[g.mapDef.Name]; ok {
+		return m, nil
+	}
+	if gen := g.mapDef.Generated; gen != nil {
+		// Сгенерированной карты нет в content/maps — пересобираем из того же seed
+		m, err := GenerateMap(gen.Template, gen.Seed, gen.Zones)
+		if err != nil {
+			return nil, fmt.Errorf("reload: %w", err)
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("reload: current map %q is missing", g.mapDef.Name)
+}
+
+// ApplyContent заново применяет текущую карту из c и рассылает клиентам
+// world_info. Глобальный контент не трогает.
+func (g *Game) ApplyContent(c *Content) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	m, err := g.contentMapLocked(c)
+	if err != nil {
+		return err
+	}
+
+	// Инстансы держат ссылки на шаблоны старой карты
+	g.closeAllDungeonsLocked("reload")
+
+	g.applyMapLocked(m)
+	g.navGrids = make(map[string]*navGrid)
+	g.pathCache = make(map[navKey]*cachedPath)
+	g.initNavigation()
+
+	// Сущности из исчезнувших зон переезжают или удаляются
+	for _, mob := range g.mobs {
+		if g.zones[mob.Zone] == nil {
+			if state := g.bosses[mob.BossID]; state != nil {
+				state.MobID = ""
+			}
+			g.despawnMobLocked(mob)
+		} else {
+			mob.Path = nil
+		}
+	}
+	for _, player := range g.players {
+		if g.zones[player.CurrentZone] == nil {
+			player.X, player.Y = g.findSafeSpawnPosition(g.spawnZone, player.ID)
+			player.CurrentZone = g.spawnZone
+		}
+		player.UnlockZone(g.spawnZone)
+		g.sendZoneObstaclesLocked(player)
+	}
+
+	g.broadcastWorldInfoLocked()
+	fmt.Printf("🔄 Content reloaded, map %q\n", m.Name)
+	return nil
+}
@@ -0,0 +1,472 @@
+package game
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"math/rand"
+	"path"
+	"sort"
+)
+
+// contentFiles — встроенный контент (см. также Game.ReloadContent)
+//
+//go:embed content/maps/*.json content/mob_ai.json
+var contentFiles embed.FS
+
+// DefaultMapName — карта, которую поднимает NewGame
+const DefaultMapName = "default"
+
+// DefaultPortalRadius — радиус портала, если в карте он не задан
+const DefaultPortalRadius = 100.0
+
+// PortalRequirements — условия прохода через портал
+type PortalRequirements struct {
+	MinLevel int    `json:"min_level,omitempty"`
+	KeyItem  string `json:"key_item,omitempty"`
+	Boss     string `json:"boss,omitempty"` // ID энкаунтера, который нужно победить
+}
+
+// SpawnRegion — прямоугольная область спавна мобов внутри зоны
+type SpawnRegion struct {
+	ID       string    `json:"id"`
+	Zone     string    `json:"zone"`
+	MinX     float64   `json:"min_x"`
+	MaxX     float64   `json:"max_x"`
+	MinY     float64   `json:"min_y"`
+	MaxY     float64   `json:"max_y"`
+	Weight   float64   `json:"weight"`
+	MobTypes []MobType `json:"mob_types,omitempty"` // пусто — типы зоны
+}
+
+// POI — точка интереса для клиента (ориентиры, логово босса и т.п.)
+type POI struct {
+	ID   string  `json:"id"`
+	Zone string  `json:"zone"`
+	Kind string  `json:"kind"`
+	Name string  `json:"name"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+}
+
+// MapDef — описание карты: зоны, порталы, области спавна и точки интереса.
+// После загрузки карта используется только на чтение и может быть общей
+// для нескольких игр.
+type MapDef struct {
+	Name         string         `json:"name"`
+	Width        float64        `json:"width"`
+	Height       float64        `json:"height"`
+	SpawnZone    string         `json:"spawn_zone"`
+	Zones        []*Zone        `json:"zones"`
+	Portals      []*Portal      `json:"portals"`
+	SpawnRegions []*SpawnRegion `json:"spawn_regions"`
+	POIs         []*POI         `json:"pois"`
+	// Dungeons — шаблоны подземелий для порталов с полем dungeon
+	Dungeons []*DungeonTemplate `json:"dungeons,omitempty"`
+	// Seamless — соседние зоны делят границу, и игроки переходят её пешком
+	Seamless bool `json:"seamless,omitempty"`
+	// Regions — безопасные, опасные области и фонтаны (см. region.go)
+	Regions []*Region `json:"regions,omitempty"`
+	// Generated — шаблон и seed, если карта построена генератором (см. mapgen.go)
+	Generated *GenInfo `json:"generated,omitempty"`
+}
+
+// Maps — карты из content/maps по имени (подменяется под contentMu, читать через lookupMap)
+var Maps = map[string]*MapDef{}
+
+func init() {
+	if err := LoadMaps(); err != nil {
+		panic(err)
+	}
+}
+
+// LoadMaps разбирает все встроенные карты и заменяет текущие
+func LoadMaps() error {
+	maps, err := loadMapsFS(contentFiles)
+	if err != nil {
+		return err
+	}
+	contentMu.Lock()
+	Maps = maps
+	contentMu.Unlock()
+	return nil
+}
+
+// loadMapsFS разбирает все карты из content/maps в fsys
+func loadMapsFS(fsys fs.FS) (map[string]*MapDef, error) {
+	entries, err := fs.ReadDir(fsys, "content/maps")
+	if err != nil {
+		return nil, fmt.Errorf("maps: %w", err)
+	}
+
+	maps := make(map[string]*MapDef)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join("content/maps", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("maps: %w", err)
+		}
+		m, err := ParseMap(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		if _, dup := maps[m.Name]; dup {
+			return nil, fmt.Errorf("%s: duplicate map name %q", entry.Name(), m.Name)
+		}
+		maps[m.Name] = m
+	}
+	return maps, nil
+}
+
+// MapNames — имена доступных карт по алфавиту
+func MapNames() []string {
+	contentMu.RLock()
+	defer contentMu.RUnlock()
+	names := make([]string, 0, len(Maps))
+	for name := range Maps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseMap разбирает карту и проверяет её
+func ParseMap(data []byte) (*MapDef, error) {
+	var m MapDef
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("map: %w", err)
+	}
+	for _, p := range m.Portals {
+		if p.Radius <= 0 {
+			p.Radius = DefaultPortalRadius
+		}
+	}
+	for _, d := range m.Dungeons {
+		if d.Objective.Kind == "" {
+			d.Objective.Kind = DungeonKillAll
+		}
+		if d.MaxPlayers <= 0 {
+			d.MaxPlayers = DefaultDungeonMaxPlayers
+		}
+		if d.TimeLimit <= 0 {
+			d.TimeLimit = DefaultDungeonTimeLimit
+		}
+		if d.EmptyTimeout <= 0 {
+			d.EmptyTimeout = DefaultDungeonEmptyTimeout
+		}
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("map %q: %w", m.Name, err)
+	}
+
+	// Раскладываем области спавна и точки интереса по зонам
+	zones := make(map[string]*Zone)
+	for _, z := range m.Zones {
+		zones[z.Name] = z
+	}
+	for _, r := range m.SpawnRegions {
+		zones[r.Zone].SpawnRegions = append(zones[r.Zone].SpawnRegions, r)
+	}
+	for _, poi := range m.POIs {
+		zones[poi.Zone].POIs = append(zones[poi.Zone].POIs, poi)
+	}
+	for _, r := range m.Regions {
+		zones[r.Zone].Regions = append(zones[r.Zone].Regions, r)
+	}
+	return &m, nil
+}
+
+// Validate проверяет карту и возвращает все найденные ошибки сразу
+func (m *MapDef) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if m.Name == "" {
+		fail("missing name")
+	}
+	if m.Width <= 0 || m.Height <= 0 {
+		fail("world size must be positive, got %.0fx%.0f", m.Width, m.Height)
+	}
+	if len(m.Zones) == 0 {
+		fail("no zones")
+	}
+
+	zones := make(map[string]*Zone)
+	for _, z := range m.Zones {
+		if z.Name == "" {
+			fail("zone without name")
+			continue
+		}
+		if _, dup := zones[z.Name]; dup {
+			fail("duplicate zone %q", z.Name)
+		}
+		zones[z.Name] = z
+
+		if z.MinX >= z.MaxX || z.MinY >= z.MaxY {
+			fail("zone %q: empty bounds", z.Name)
+		}
+		if z.MinX < 0 || z.MinY < 0 || z.MaxX > m.Width || z.MaxY > m.Height {
+			fail("zone %q: outside world bounds", z.Name)
+		}
+		for _, other := range m.Zones {
+			if other != z && other.Name < z.Name && z.overlaps(other) {
+				fail("zones %q and %q overlap", other.Name, z.Name)
+			}
+		}
+
+		total := 0.0
+		for rarity, chance := range z.RarityDistribution {
+			if _, ok := RarityMultipliers[rarity]; !ok {
+				fail("zone %q: unknown rarity %q", z.Name, rarity)
+			}
+			if chance < 0 {
+				fail("zone %q: negative chance for %q", z.Name, rarity)
+			}
+			total += chance
+		}
+		if len(z.RarityDistribution) > 0 && math.Abs(total-1) > 0.001 {
+			fail("zone %q: rarity chances sum to %.3f, want 1", z.Name, total)
+		}
+		for _, mobType := range z.MobTypes {
+			if err := validateSpawnableMob(mobType); err != nil {
+				fail("zone %q: %v", z.Name, err)
+			}
+		}
+		if z.MaxMobs < 0 {
+			fail("zone %q: negative max_mobs", z.Name)
+		}
+		for _, o := range z.Obstacles {
+			if err := o.validate(); err != nil {
+				fail("zone %q: %v", z.Name, err)
+			}
+		}
+	}
+
+	// На бесшовной карте каждая зона должна граничить хотя бы с одной другой
+	if m.Seamless && len(m.Zones) > 1 {
+		for _, z := range m.Zones {
+			connected := false
+			for _, other := range m.Zones {
+				if other != z && z.touches(other) {
+					connected = true
+					break
+				}
+			}
+			if !connected {
+				fail("zone %q: seamless map zone shares no boundary with other zones", z.Name)
+			}
+		}
+	}
+
+	if _, ok := zones[m.SpawnZone]; !ok {
+		fail("spawn zone %q does not exist", m.SpawnZone)
+	}
+
+	portals := make(map[string]*Portal)
+	for _, p := range m.Portals {
+		if _, dup := portals[p.ID]; dup {
+			fail("duplicate portal %q", p.ID)
+		}
+		portals[p.ID] = p
+	}
+	for _, p := range m.Portals {
+		zone, ok := zones[p.Zone]
+		if !ok {
+			fail("portal %q: unknown zone %q", p.ID, p.Zone)
+		} else if !zone.Contains(p.X, p.Y) {
+			fail("portal %q: (%.0f, %.0f) is outside zone %q", p.ID, p.X, p.Y, p.Zone)
+		}
+		if p.Dungeon != "" {
+			if m.dungeon(p.Dungeon) == nil {
+				fail("portal %q: unknown dungeon %q", p.ID, p.Dungeon)
+			}
+			if p.To != "" {
+				fail("portal %q: dungeon portal cannot have a destination", p.ID)
+			}
+		} else if dest, ok := portals[p.To]; !ok {
+			fail("portal %q: destination %q does not exist", p.ID, p.To)
+		} else if dest.ID == p.ID {
+			fail("portal %q: leads to itself", p.ID)
+		}
+		if p.Requirements.MinLevel < 0 {
+			fail("portal %q: negative min_level", p.ID)
+		}
+		if item := p.Requirements.KeyItem; item != "" {
+			if _, ok := PetalConfigs[PetalType(item)]; !ok {
+				fail("portal %q: unknown key item %q", p.ID, item)
+			}
+		}
+		if boss := p.Requirements.Boss; boss != "" && !bossEncounterExists(boss) {
+			fail("portal %q: unknown boss %q", p.ID, boss)
+		}
+	}
+
+	for _, r := range m.SpawnRegions {
+		zone, ok := zones[r.Zone]
+		if !ok {
+			fail("spawn region %q: unknown zone %q", r.ID, r.Zone)
+			continue
+		}
+		if r.MinX >= r.MaxX || r.MinY >= r.MaxY {
+			fail("spawn region %q: empty bounds", r.ID)
+		}
+		if r.MinX < zone.MinX || r.MaxX > zone.MaxX || r.MinY < zone.MinY || r.MaxY > zone.MaxY {
+			fail("spawn region %q: outside zone %q", r.ID, r.Zone)
+		}
+		if r.Weight <= 0 {
+			fail("spawn region %q: weight must be positive", r.ID)
+		}
+		for _, mobType := range r.MobTypes {
+			if err := validateSpawnableMob(mobType); err != nil {
+				fail("spawn region %q: %v", r.ID, err)
+			}
+		}
+	}
+
+	regions := make(map[string]bool)
+	for _, r := range m.Regions {
+		if regions[r.ID] {
+			fail("duplicate region %q", r.ID)
+		}
+		regions[r.ID] = true
+		zone, ok := zones[r.Zone]
+		if !ok {
+			fail("region %q: unknown zone %q", r.ID, r.Zone)
+			continue
+		}
+		if err := r.validate(zone); err != nil {
+			fail("%v", err)
+		}
+	}
+
+	dungeons := make(map[string]bool)
+	for _, d := range m.Dungeons {
+		if dungeons[d.ID] {
+			fail("duplicate dungeon %q", d.ID)
+		}
+		dungeons[d.ID] = true
+		errs = append(errs, d.validate()...)
+	}
+
+	for _, poi := range m.POIs {
+		zone, ok := zones[poi.Zone]
+		if !ok {
+			fail("poi %q: unknown zone %q", poi.ID, poi.Zone)
+		} else if !zone.Contains(poi.X, poi.Y) {
+			fail("poi %q: outside zone %q", poi.ID, poi.Zone)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateSpawnableMob(mobType MobType) error {
+	config, ok := MobConfigs[mobType]
+	if !ok {
+		return fmt.Errorf("unknown mob type %q", mobType)
+	}
+	if config.Unique {
+		return fmt.Errorf("mob type %q is unique and cannot be spawned by the zone", mobType)
+	}
+	return nil
+}
+
+// Contains — лежит ли точка внутри зоны
+func (z *Zone) Contains(x, y float64) bool {
+	return x >= z.MinX && x <= z.MaxX && y >= z.MinY && y <= z.MaxY
+}
+
+func (z *Zone) overlaps(other *Zone) bool {
+	return z.MinX < other.MaxX && other.MinX < z.MaxX && z.MinY < other.MaxY && other.MinY < z.MaxY
+}
+
+// rollRarity — редкость нового моба по распределению зоны
+// (если в карте его нет — по общей таблице ZoneRarityDistribution)
+func (z *Zone) rollRarity() Rarity {
+	if len(z.RarityDistribution) == 0 {
+		return getRandomRarity(z.Name)
+	}
+
+	// Идём в фиксированном порядке, чтобы результат зависел только от броска
+	r := rand.Float64()
+	cumulative := 0.0
+	for _, rarity := range rarityOrder {
+		cumulative += z.RarityDistribution[rarity]
+		if r <= cumulative {
+			return rarity
+		}
+	}
+	return RarityCommon
+}
+
+// affixSettings — настройки аффиксов зоны (с откатом на ZoneAffixSettings)
+func (z *Zone) affixSettings() AffixZoneSettings {
+	if z.Affixes != nil {
+		return *z.Affixes
+	}
+	return ZoneAffixSettings[z.Name]
+}
+
+// spawnableMobTypes — типы мобов для обычного спавна в зоне
+func (z *Zone) spawnableMobTypes(region *SpawnRegion) []MobType {
+	if region != nil && len(region.MobTypes) > 0 {
+		return region.MobTypes
+	}
+	if len(z.MobTypes) > 0 {
+		return z.MobTypes
+	}
+	types := make([]MobType, 0, len(MobConfigs))
+	for mobType, config := range MobConfigs {
+		if !config.Unique {
+			types = append(types, mobType)
+		}
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// pickSpawnRegion — взвешенный выбор области спавна (nil — вся зона)
+func (z *Zone) pickSpawnRegion() *SpawnRegion {
+	total := 0.0
+	for _, r := range z.SpawnRegions {
+		total += r.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+	roll := rand.Float64() * total
+	for _, r := range z.SpawnRegions {
+		roll -= r.Weight
+		if roll < 0 {
+			return r
+		}
+	}
+	return z.SpawnRegions[len(z.SpawnRegions)-1]
+}
+
+// applyMapLocked — раскладывает карту по зонам и порталам игры
+func (g *Game) applyMapLocked(m *MapDef) {
+	g.mapDef = m
+	g.worldWidth = m.Width
+	g.worldHeight = m.Height
+	g.spawnZone = m.SpawnZone
+
+	g.zones = make(map[string]*Zone)
+	for _, z := range m.Zones {
+		g.zones[z.Name] = z
+	}
+
+	g.portals = make(map[string]*Portal)
+	for _, p := range m.Portals {
+		g.portals[p.ID] = p
+	}
+
+	fmt.Printf("✅ Map %q loaded: %d zones, %d portals\n", m.Name, len(m.Zones), len(m.Portals))
+}
@@ -0,0 +1,185 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+// testMapJSON — минимальная корректная карта из двух зон с парой порталов
+const testMapJSON = `{
+  "name": "test",
+  "width": 2000,
+  "height": 1000,
+  "spawn_zone": "a",
+  "zones": [
+    {"name": "a", "min_x": 0, "max_x": 900, "min_y": 0, "max_y": 1000,
+     "rarity": {"common": 0.7, "uncommon": 0.3}, "mob_types": ["goblin"], "max_mobs": 5},
+    {"name": "b", "min_x": 1000, "max_x": 2000, "min_y": 0, "max_y": 1000,
+     "rarity": {"common": 1}, "mob_types": ["wolf"], "max_mobs": 5}
+  ],
+  "portals": [
+    {"id": "a_out", "zone": "a", "x": 850, "y": 500, "to": "b_in"},
+    {"id": "b_in", "zone": "b", "x": 1050, "y": 500, "to": "a_out"}
+  ],
+  "spawn_regions": [
+    {"id": "a_field", "zone": "a", "min_x": 100, "max_x": 800, "min_y": 100, "max_y": 900, "weight": 1}
+  ],
+  "regions": [
+    {"id": "a_camp", "zone": "a", "kind": "safe", "shape": "circle", "x": 200, "y": 500, "radius": 100}
+  ],
+  "dungeons": [
+    {"id": "crypt", "name": "Crypt",
+     "zone": {"min_x": 0, "max_x": 1000, "min_y": 0, "max_y": 1000, "rarity": {"common": 1}, "max_mobs": 4},
+     "entry": {"x": 100, "y": 500}, "exit": {"x": 150, "y": 500},
+     "objective": {"kind": "kill_count", "count": 3}}
+  ]
+}`
+
+func TestParseMapValid(t *testing.T) {
+	m, err := ParseMap([]byte(testMapJSON))
+	if err != nil {
+		t.Fatalf("valid map rejected: %v", err)
+	}
+	if len(m.Zones[0].SpawnRegions) != 1 || len(m.Zones[0].Regions) != 1 {
+		t.Errorf("spawn regions and regions are not attached to zone %q", m.Zones[0].Name)
+	}
+	if m.Portals[0].Radius != DefaultPortalRadius {
+		t.Errorf("portal radius %v, want default %v", m.Portals[0].Radius, DefaultPortalRadius)
+	}
+}
+
+func TestParseMapValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     string // фрагмент testMapJSON, который заменяется
+		new     string
+		wantErr string
+	}{
+		{
+			name:    "unknown portal destination",
+			old:     `"to": "b_in"`,
+			new:     `"to": "nowhere"`,
+			wantErr: `portal "a_out": destination "nowhere" does not exist`,
+		},
+		{
+			name:    "portal leads to itself",
+			old:     `"to": "b_in"`,
+			new:     `"to": "a_out"`,
+			wantErr: `portal "a_out": leads to itself`,
+		},
+		{
+			name:    "portal outside its zone",
+			old:     `"x": 850, "y": 500`,
+			new:     `"x": 950, "y": 500`,
+			wantErr: `portal "a_out": (950, 500) is outside zone "a"`,
+		},
+		{
+			name:    "zones overlap",
+			old:     `"min_x": 1000, "max_x": 2000`,
+			new:     `"min_x": 800, "max_x": 2000`,
+			wantErr: `zones "a" and "b" overlap`,
+		},
+		{
+			name:    "zone outside the world",
+			old:     `"min_x": 1000, "max_x": 2000`,
+			new:     `"min_x": 1000, "max_x": 2500`,
+			wantErr: `zone "b": outside world bounds`,
+		},
+		{
+			name:    "rarity chances do not sum to one",
+			old:     `"uncommon": 0.3`,
+			new:     `"uncommon": 0.2`,
+			wantErr: `zone "a": rarity chances sum to 0.900, want 1`,
+		},
+		{
+			name:    "unknown rarity",
+			old:     `"uncommon": 0.3`,
+			new:     `"mythic": 0.3`,
+			wantErr: `zone "a": unknown rarity "mythic"`,
+		},
+		{
+			name:    "unknown spawn zone",
+			old:     `"spawn_zone": "a"`,
+			new:     `"spawn_zone": "c"`,
+			wantErr: `spawn zone "c" does not exist`,
+		},
+		{
+			name:    "duplicate zone",
+			old:     `{"name": "b",`,
+			new:     `{"name": "a",`,
+			wantErr: `duplicate zone "a"`,
+		},
+		{
+			name:    "spawn region outside its zone",
+			old:     `"min_x": 100, "max_x": 800`,
+			new:     `"min_x": 100, "max_x": 950`,
+			wantErr: `spawn region "a_field": outside zone "a"`,
+		},
+		{
+			name:    "spawn region in unknown zone",
+			old:     `{"id": "a_field", "zone": "a"`,
+			new:     `{"id": "a_field", "zone": "c"`,
+			wantErr: `spawn region "a_field": unknown zone "c"`,
+		},
+		{
+			name:    "region outside its zone",
+			old:     `"x": 200, "y": 500, "radius": 100`,
+			new:     `"x": 1200, "y": 500, "radius": 100`,
+			wantErr: `region "a_camp": center is outside zone "a"`,
+		},
+		{
+			name:    "region with unknown kind",
+			old:     `"kind": "safe"`,
+			new:     `"kind": "lava"`,
+			wantErr: `region "a_camp": unknown kind "lava"`,
+		},
+		{
+			name:    "unknown mob type",
+			old:     `"mob_types": ["wolf"]`,
+			new:     `"mob_types": ["dragon"]`,
+			wantErr: `zone "b":`,
+		},
+		{
+			name:    "dungeon kill count above its mobs",
+			old:     `"count": 3`,
+			new:     `"count": 5`,
+			wantErr: `dungeon "crypt": kill_count objective needs 5 kills, but only 4 mobs spawn`,
+		},
+		{
+			name:    "unknown key item",
+			old:     `"to": "a_out"}`,
+			new:     `"to": "a_out", "requirements": {"key_item": "feather"}}`,
+			wantErr: `portal "b_in": unknown key item "feather"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(testMapJSON, tt.old) {
+				t.Fatalf("fragment %q not found in test map", tt.old)
+			}
+			data := strings.Replace(testMapJSON, tt.old, tt.new, 1)
+			_, err := ParseMap([]byte(data))
+			if err == nil {
+				t.Fatalf("map accepted, want error containing %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %q does not contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseMapCollectsAllErrors(t *testing.T) {
+	data := strings.Replace(testMapJSON, `"to": "b_in"`, `"to": "nowhere"`, 1)
+	data = strings.Replace(data, `"uncommon": 0.3`, `"uncommon": 0.2`, 1)
+	_, err := ParseMap([]byte(data))
+	if err == nil {
+		t.Fatal("broken map accepted")
+	}
+	for _, want := range []string{`destination "nowhere"`, `rarity chances sum`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
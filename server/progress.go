@@ -0,0 +1,30 @@
+package server
+
+import (
+	"mpg/server/game"
+	"mpg/server/user"
+)
+
+// userProgressStore сохраняет прогресс игроков в документах пользователей
+type userProgressStore struct {
+	users *user.Repository
+}
+
+func (s userProgressStore) SaveProgress(userID string, progress game.PlayerProgress) error {
+	return s.users.SaveProgress(userID, user.Progress{
+		Level:          progress.Level,
+		XP:             progress.XP,
+		UnlockedZones:  progress.UnlockedZones,
+		DefeatedBosses: progress.DefeatedBosses,
+	})
+}
+
+// progressFromUser — сохранённый прогресс пользователя для AddPlayer
+func progressFromUser(u *user.User) game.PlayerProgress {
+	return game.PlayerProgress{
+		Level:          u.Progress.Level,
+		XP:             u.Progress.XP,
+		UnlockedZones:  u.Progress.UnlockedZones,
+		DefeatedBosses: u.Progress.DefeatedBosses,
+	}
+}
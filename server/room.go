@@ -0,0 +1,237 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"mpg/server/game"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// RoomMode — режим комнаты (показывается в браузере серверов)
+type RoomMode string
+
+const (
+	RoomModeAdventure RoomMode = "adventure" // открытый мир с порталами и боссами
+	RoomModeArena     RoomMode = "arena"     // небольшая карта для быстрых боёв
+)
+
+// RoomConfig — описание комнаты
+type RoomConfig struct {
+	ID         string
+	Name       string
+	Map        string
+	Mode       RoomMode
+	MaxPlayers int
+}
+
+// DefaultRooms — комнаты, которые поднимает сервер
+var DefaultRooms = []RoomConfig{
+	{ID: "main", Name: "Main World", Map: game.DefaultMapName, Mode: RoomModeAdventure, MaxPlayers: 50},
+	{ID: "open", Name: "Open World", Map: "open_world", Mode: RoomModeAdventure, MaxPlayers: 50},
+	{ID: "arena", Name: "Arena", Map: "arena", Mode: RoomModeArena, MaxPlayers: 20},
+}
+
+// Ошибки входа в комнату
+var (
+	ErrRoomNotFound = errors.New("room not found")
+	ErrRoomFull     = errors.New("room is full")
+)
+
+// Room — независимая игра со своей картой и лимитом игроков
+type Room struct {
+	RoomConfig
+	Game *game.Game
+}
+
+// RoomInfo — комната в списке /api/rooms
+type RoomInfo struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Map        string   `json:"map"`
+	Mode       RoomMode `json:"mode"`
+	Players    int      `json:"players"`
+	MaxPlayers int      `json:"max_players"`
+	Full       bool     `json:"full"`
+}
+
+func (r *Room) info() RoomInfo {
+	players := r.Game.GetPlayersCount()
+	return RoomInfo{
+		ID:         r.ID,
+		Name:       r.Name,
+		Map:        r.Map,
+		Mode:       r.Mode,
+		Players:    players,
+		MaxPlayers: r.MaxPlayers,
+		Full:       players >= r.MaxPlayers,
+	}
+}
+
+// RoomManager — набор комнат одного процесса
+type RoomManager struct {
+	mu    sync.Mutex // держится между проверкой лимита и добавлением игрока и при изменении списка
+	rooms map[string]*Room
+	order []string // порядок комнат из конфигурации
+}
+
+// NewRoomManager поднимает игру для каждой комнаты
+func NewRoomManager(configs []RoomConfig) (*RoomManager, error) {
+	m := &RoomManager{rooms: make(map[string]*Room)}
+	for _, config := range configs {
+		if _, dup := m.rooms[config.ID]; dup {
+			return nil, fmt.Errorf("duplicate room %q", config.ID)
+		}
+		worldMap, err := game.ResolveMap(config.Map)
+		if err != nil {
+			return nil, fmt.Errorf("room %q: %w", config.ID, err)
+		}
+		if config.MaxPlayers <= 0 {
+			return nil, fmt.Errorf("room %q: max players must be positive", config.ID)
+		}
+		m.rooms[config.ID] = &Room{RoomConfig: config, Game: game.NewGameWithMap(worldMap)}
+		m.order = append(m.order, config.ID)
+		fmt.Printf("🏠 Room %q started on map %q\n", config.ID, config.Map)
+	}
+	if len(m.rooms) == 0 {
+		return nil, errors.New("no rooms configured")
+	}
+	return m, nil
+}
+
+// Rooms — все комнаты в порядке конфигурации
+func (m *RoomManager) Rooms() []*Room {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.roomsLocked()
+}
+
+func (m *RoomManager) roomsLocked() []*Room {
+	rooms := make([]*Room, 0, len(m.order))
+	for _, id := range m.order {
+		rooms = append(rooms, m.rooms[id])
+	}
+	return rooms
+}
+
+// List — комнаты с текущим населением
+func (m *RoomManager) List() []RoomInfo {
+	infos := make([]RoomInfo, 0, len(m.order))
+	for _, room := range m.Rooms() {
+		infos = append(infos, room.info())
+	}
+	return infos
+}
+
+// Join добавляет игрока в комнату roomID или, если она пуста,
+// в наименее заполненную
+func (m *RoomManager) Join(roomID string, conn *websocket.Conn, userID, username string, progress game.PlayerProgress) (*Room, *game.Player, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var room *Room
+	if roomID != "" {
+		room = m.rooms[roomID]
+		if room == nil {
+			return nil, nil, ErrRoomNotFound
+		}
+		if room.Game.GetPlayersCount() >= room.MaxPlayers {
+			return nil, nil, ErrRoomFull
+		}
+	} else {
+		room = m.leastFullLocked()
+		if room == nil {
+			return nil, nil, ErrRoomFull
+		}
+	}
+
+	player := room.Game.AddPlayer(conn, userID, username, progress)
+	return room, player, nil
+}
+
+// SetProgressStore — куда комнаты сохраняют прогресс игроков
+func (m *RoomManager) SetProgressStore(store game.ProgressStore) {
+	for _, room := range m.Rooms() {
+		room.Game.SetProgressStore(store)
+	}
+}
+
+// Remove останавливает комнату и убирает её из списка. Игроки комнаты
+// отключаются.
+func (m *RoomManager) Remove(roomID string) error {
+	m.mu.Lock()
+	room := m.rooms[roomID]
+	if room == nil {
+		m.mu.Unlock()
+		return ErrRoomNotFound
+	}
+	delete(m.rooms, roomID)
+	m.order = slices.DeleteFunc(m.order, func(id string) bool { return id == roomID })
+	m.mu.Unlock()
+
+	room.Game.Close()
+	fmt.Printf("🏠 Room %q stopped\n", roomID)
+	return nil
+}
+
+// Close останавливает все комнаты
+func (m *RoomManager) Close() {
+	for _, room := range m.Rooms() {
+		m.Remove(room.ID)
+	}
+}
+
+// leastFullLocked — комната с наименьшей долей занятых мест (nil — все заполнены)
+func (m *RoomManager) leastFullLocked() *Room {
+	type candidate struct {
+		room *Room
+		fill float64
+	}
+	var candidates []candidate
+	for _, room := range m.roomsLocked() {
+		players := room.Game.GetPlayersCount()
+		if players >= room.MaxPlayers {
+			continue
+		}
+		candidates = append(candidates, candidate{room, float64(players) / float64(room.MaxPlayers)})
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	// Стабильная сортировка сохраняет порядок конфигурации при равной заполненности
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].fill < candidates[j].fill })
+	return candidates[0].room
+}
+
+// ReloadContent перечитывает контент один раз и пересобирает все комнаты.
+// Если карта хоть одной комнаты пропала, ничего не меняется.
+func (m *RoomManager) ReloadContent(fsys fs.FS) error {
+	content, err := game.LoadContent(fsys)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, room := range m.Rooms() {
+		if err := room.Game.CheckContent(content); err != nil {
+			errs = append(errs, fmt.Errorf("room %q: %w", room.ID, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	// Глобальный контент подменяется один раз, затем каждая комната
+	// пересобирает свои зоны под своим мьютексом
+	game.InstallContent(content)
+	for _, room := range m.Rooms() {
+		if err := room.Game.ApplyContent(content); err != nil {
+			errs = append(errs, fmt.Errorf("room %q: %w", room.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
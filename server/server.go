@@ -4,10 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"log"
 	"mpg/server/game"
 	"mpg/server/user"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -17,9 +21,11 @@ import (
 
 type Server struct {
 	addr   string
-	game   *game.Game
+	rooms  *RoomManager
 	client *mongo.Client
 	users  *user.Repository
+	// debugAI — отладка ИИ мобов доступна всем (MPG_DEBUG_AI=1), а не только админам
+	debugAI bool
 }
 
 type AuthRequest struct {
@@ -54,24 +60,58 @@ func NewServer(addr string) *Server {
 	db := client.Database("mpg")
 	userRepo := user.NewRepository(db)
 
+	// MPG_MAP меняет карту первой комнаты (по умолчанию — default);
+	// gen:<шаблон>:<seed>[:<зон>] — сгенерированная карта
+	rooms := append([]RoomConfig(nil), DefaultRooms...)
+	if mapName := os.Getenv("MPG_MAP"); mapName != "" {
+		rooms[0].Map = mapName
+	}
+	roomManager, err := NewRoomManager(rooms)
+	if err != nil {
+		log.Fatal("Failed to start rooms: ", err)
+	}
+	roomManager.SetProgressStore(userProgressStore{userRepo})
+
 	return &Server{
-		addr:   addr,
-		game:   game.NewGame(),
-		client: client,
-		users:  userRepo,
+		addr:    addr,
+		rooms:   roomManager,
+		client:  client,
+		users:   userRepo,
+		debugAI: os.Getenv("MPG_DEBUG_AI") == "1",
 	}
 }
 
 func (s *Server) Start() error {
+	go s.watchContentReload()
 
 	// Оставить только API
 	http.HandleFunc("/api/register", s.handleRegister)
 	http.HandleFunc("/api/login", s.handleLogin)
+	http.HandleFunc("/api/metrics", s.handleMetrics)
+	http.HandleFunc("/api/rooms", s.handleRooms)
 	http.HandleFunc("/ws", s.handleWebSocket)
 
 	return http.ListenAndServe(s.addr, nil)
 }
 
+// watchContentReload перечитывает контент игры по SIGHUP.
+// Если задан MPG_CONTENT_DIR, контент читается с диска (каталог с папкой content),
+// иначе — встроенный.
+func (s *Server) watchContentReload() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	for range signals {
+		var fsys fs.FS
+		if dir := os.Getenv("MPG_CONTENT_DIR"); dir != "" {
+			fsys = os.DirFS(dir)
+		}
+		if err := s.rooms.ReloadContent(fsys); err != nil {
+			log.Println("Content reload failed:", err)
+		}
+	}
+}
+
 func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -140,7 +180,37 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// handleRooms — список комнат для браузера серверов
+func (s *Server) handleRooms(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rooms": s.rooms.List(),
+	})
+}
+
+// handleMetrics — число игроков и состояние инстансов подземелий по комнатам
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	rooms := make(map[string]interface{})
+	total := 0
+	for _, room := range s.rooms.Rooms() {
+		players := room.Game.GetPlayersCount()
+		total += players
+		rooms[room.ID] = map[string]interface{}{
+			"players":  players,
+			"dungeons": room.Game.DungeonMetrics(),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"players": total,
+		"rooms":   rooms,
+	})
+}
+
 func (s *Server) Close() error {
+	s.rooms.Close()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	return s.client.Disconnect(ctx)
@@ -182,12 +252,25 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	username := user.Login // or user.Username, depending on your struct
 	userID := token        // this is the MongoDB ID (hex string)
 
-	// Создаем нового игрока, передавая соединение и userID
-	player := s.game.AddPlayer(ws, userID, username)
-	defer s.game.RemovePlayer(player.ID)
+	// Комната из ?room=..., без параметра — наименее заполненная
+	room, player, err := s.rooms.Join(r.URL.Query().Get("room"), ws, userID, username, progressFromUser(user))
+	if err != nil {
+		ws.WriteJSON(map[string]interface{}{
+			"type":    "error",
+			"message": "Cannot join room: " + err.Error(),
+		})
+		return
+	}
+	g := room.Game
+	defer g.RemovePlayer(player.ID)
+
+	ws.WriteJSON(map[string]interface{}{
+		"type": "room_joined",
+		"data": room.info(),
+	})
 
 	// Отправляем начальное состояние
-	initialState := s.game.GetGameState(player.ID)
+	initialState := g.GetGameState(player.ID)
 	if err := ws.WriteJSON(initialState); err != nil {
 		fmt.Println("Error sending initial state:", err)
 		return
@@ -207,10 +290,43 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 				dx, _ := moveData["dx"].(float64)
 				dy, _ := moveData["dy"].(float64)
 
-				s.game.MovePlayer(player.ID, dx, dy)
+				g.MovePlayer(player.ID, dx, dy)
+			}
+		case "stance":
+			if stanceData, ok := msg.Data.(map[string]interface{}); ok {
+				stance, _ := stanceData["stance"].(string)
+				g.SetPlayerStance(player.ID, game.Stance(stance))
+			}
+		case "debug_mob":
+			// Внутренности ИИ видят только админы или все в отладочном режиме
+			if !user.Admin && !s.debugAI {
+				ws.WriteJSON(map[string]interface{}{
+					"type":    "error",
+					"message": "Debug commands are not allowed",
+				})
+				continue
+			}
+			if debugData, ok := msg.Data.(map[string]interface{}); ok {
+				mobID, _ := debugData["id"].(string)
+				g.WatchMobAI(player.ID, mobID)
+			}
+		case "party":
+			// Группы создаёт сервер: приглашение, принятие, выход
+			if partyData, ok := msg.Data.(map[string]interface{}); ok {
+				action, _ := partyData["action"].(string)
+				switch action {
+				case "invite":
+					targetID, _ := partyData["player_id"].(string)
+					g.InvitePlayerToParty(player.ID, targetID)
+				case "accept":
+					partyID, _ := partyData["id"].(string)
+					g.AcceptPartyInvite(player.ID, partyID)
+				case "leave":
+					g.LeaveParty(player.ID)
+				}
 			}
 		case "respawn": 
-			s.game.RespawnPlayer(player.ID)
+			g.RespawnPlayer(player.ID)
 		case "ping":
 			ws.WriteJSON(game.GameMessage{Type: "pong"})
 		}
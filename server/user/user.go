@@ -15,9 +15,19 @@ type User struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	Login     string             `bson:"login" json:"login"`
 	Password  string             `bson:"password" json:"-"`
+	Admin     bool               `bson:"admin,omitempty" json:"-"` // доступ к отладочным командам
+	Progress  Progress           `bson:"progress" json:"-"`
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
 }
 
+// Progress — уровень, опыт и открытые зоны игрока между сессиями
+type Progress struct {
+	Level          int             `bson:"level"`
+	XP             int             `bson:"xp"`
+	UnlockedZones  map[string]bool `bson:"unlocked_zones,omitempty"`
+	DefeatedBosses map[string]bool `bson:"defeated_bosses,omitempty"`
+}
+
 type Repository struct {
 	collection *mongo.Collection
 }
@@ -80,6 +90,23 @@ func (r *Repository) ValidateCredentials(login, password string) (*User, error)
 	return user, nil
 }
 
+// SaveProgress перезаписывает прогресс пользователя
+func (r *Repository) SaveProgress(id string, progress Progress) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = r.collection.UpdateByID(ctx, objID, bson.M{"$set": bson.M{"progress": progress}})
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
 func (r *Repository) GetUserByID(id string) (*User, error) {
 	// Convert hex string to ObjectID
 	objID, err := primitive.ObjectIDFromHex(id)